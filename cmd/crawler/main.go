@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"errors"
 	"flag"
 	"fmt"
 	"kitchen/webcrawler/crawler"
@@ -13,6 +12,7 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -66,14 +66,18 @@ func main() {
 	fmt.Println("Press Ctrl-C to stop")
 	fmt.Println()
 
-	visitedURLs := c.Start(ctx, *startURL, *depth)
+	report, err := c.Start(ctx, *startURL, *depth)
+	if err != nil {
+		log.Fatalf("Failed to crawl: %v\n", err)
+	}
 
 	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Printf("Crawl complete! Visited %d page(s)\n", len(visitedURLs))
+	fmt.Printf("Crawl complete! Visited %d page(s) in %s\n", len(report.VisitedURLs), report.Duration.Round(time.Millisecond))
+	fmt.Printf("Downloaded: %d byte(s), failed: %d page(s)\n", report.TotalBytes, len(report.Errors))
 	fmt.Printf("Pages saved to: %s\n", *destDir)
 	fmt.Println(strings.Repeat("=", 60))
 
-	if errors.Is(ctx.Err(), context.Canceled) {
+	if report.Interrupted {
 		fmt.Println("Crawl was interrupted. Resume by running the same command again.")
 		os.Exit(130)
 	}
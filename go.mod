@@ -2,4 +2,7 @@ module kitchen
 
 go 1.25.1
 
-require golang.org/x/net v0.46.0
+require (
+	golang.org/x/net v0.46.0
+	golang.org/x/time v0.15.0
+)
@@ -0,0 +1,92 @@
+package crawler
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// claimAsset marks an asset URL as claimed and reports whether it had not been claimed
+// yet. Assets are tracked apart from the visited pages so that they are not counted as
+// visited.
+func (c *Crawler) claimAsset(rawURL string) bool {
+	key := visitKey(rawURL)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, claimed := c.claimedAssets[key]; claimed {
+		return false
+	}
+
+	c.claimedAssets[key] = struct{}{}
+	return true
+}
+
+// downloadAssets downloads and saves the assets that have not been claimed or stored yet.
+// Assets are not parsed for links, and failures are logged without stopping the crawl.
+// When robots.txt is respected, assets it disallows are skipped. depth is the depth that
+// the assets are logged with.
+func (c *Crawler) downloadAssets(ctx context.Context, assets []string, depth int) {
+	for _, asset := range assets {
+		if ctx.Err() != nil {
+			return
+		}
+
+		assetCtx := withVisit(ctx, depth, time.Now())
+
+		if !c.claimAsset(asset) {
+			continue
+		}
+
+		key := c.fileNamer.Name(asset)
+
+		if exists, err := c.storage.Exists(key); err == nil && exists {
+			continue
+		}
+
+		uri, err := url.Parse(asset)
+		if err != nil {
+			c.logger.Warn("failed to download asset", logAttrs(assetCtx, asset, "error", err)...)
+			continue
+		}
+
+		if c.robots.Respect {
+			allowed, err := c.robotsAllowed(assetCtx, uri)
+			if err != nil {
+				return
+			}
+
+			if !allowed {
+				c.logger.Debug("skipping asset", logAttrs(assetCtx, asset, "error", ErrDisallowedByRobots)...)
+				continue
+			}
+		}
+
+		select {
+		case c.semaphore <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		if err := c.waitResumed(ctx); err != nil {
+			<-c.semaphore
+			return
+		}
+
+		if err := c.waitHost(ctx, uri.Host); err != nil {
+			<-c.semaphore
+			return
+		}
+
+		_, _, err = c.downloadWithRetry(ctx, asset, key, CacheEntry{})
+		<-c.semaphore
+
+		if err != nil {
+			c.logger.Warn("failed to download asset", logAttrs(assetCtx, asset, "error", err)...)
+			continue
+		}
+
+		c.logger.Debug("downloaded asset", logAttrs(assetCtx, asset)...)
+	}
+}
@@ -0,0 +1,170 @@
+package crawler
+
+import (
+	"context"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCrawler_FindLinksAssets(t *testing.T) {
+	uri, err := url.Parse("http://localhost.com/blog")
+	assert.Nil(t, err)
+
+	page := `
+		<head>
+			<link rel="stylesheet" href="/static/site.css">
+			<link rel="icon" href="/favicon.ico">
+			<script src="/static/app.js"></script>
+			<script>inline()</script>
+		</head>
+		<body>
+			<a href="/blog/post">Post</a>
+			<img src="images/cover.png">
+			<img src="data:image/png;base64,iVBORw0KGgo=">
+			<img src="https://cdn.example.com/logo.png">
+			<video><source src="/media/intro.mp4"></video>
+		</body>`
+
+	t.Run("extracts same host assets", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir(), WithExtractAssets(true))
+		assert.Nil(t, err)
+
+		result := crawler.FindLinks(uri, strings.NewReader(page))
+		slices.Sort(result.Assets)
+
+		assert.Equal(t, result.Navigation, []string{"http://localhost.com/blog/post"})
+		assert.Equal(t, result.Assets, []string{
+			"http://localhost.com/images/cover.png",
+			"http://localhost.com/media/intro.mp4",
+			"http://localhost.com/static/app.js",
+			"http://localhost.com/static/site.css",
+		})
+	})
+
+	t.Run("extracts external assets", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir(),
+			WithExtractAssets(true),
+			WithDownloadExternalAssets(true),
+		)
+		assert.Nil(t, err)
+
+		result := crawler.FindLinks(uri, strings.NewReader(page))
+		assert.Equal(t, result.Navigation, []string{"http://localhost.com/blog/post"})
+		assert.Equal(t, len(result.Assets), 5)
+		assert.True(t, slices.Contains(result.Assets, "https://cdn.example.com/logo.png"))
+	})
+
+	t.Run("ignores assets by default", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir())
+		assert.Nil(t, err)
+
+		result := crawler.FindLinks(uri, strings.NewReader(page))
+		assert.Equal(t, len(result.Assets), 0)
+		assert.Equal(t, result.Navigation, []string{"http://localhost.com/blog/post"})
+	})
+
+	t.Run("separates pagination links from assets", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir(),
+			WithExtractAssets(true),
+			WithFollowPagination(true),
+		)
+		assert.Nil(t, err)
+
+		result := crawler.FindLinks(uri, strings.NewReader(`
+			<link rel="next" href="/blog/page/2">
+			<link rel="stylesheet" href="/blog/print.css">
+			<a href="/blog/post"><img src="/blog/thumb.png"></a>`))
+		slices.Sort(result.Navigation)
+		slices.Sort(result.Assets)
+
+		assert.Equal(t, result.Navigation, []string{"http://localhost.com/blog/page/2", "http://localhost.com/blog/post"})
+		assert.Equal(t, result.Assets, []string{"http://localhost.com/blog/print.css", "http://localhost.com/blog/thumb.png"})
+	})
+
+	t.Run("finds navigation links only", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir(), WithExtractAssets(true))
+		assert.Nil(t, err)
+
+		links := crawler.FindNavigationLinks(uri, strings.NewReader(page))
+		assert.Equal(t, links, []string{"http://localhost.com/blog/post"})
+	})
+}
+
+func TestCrawler_DownloadAssets(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+	)
+
+	httpClient.Request(link, func() (int, string) {
+		return http.StatusOK, `<img src="/logo.svg"><a href="/about">About</a>`
+	})
+
+	httpClient.Request(link+"/about", func() (int, string) {
+		return http.StatusOK, `<img src="/logo.svg">`
+	})
+
+	httpClient.Request(link+"/logo.svg", func() (int, string) {
+		return http.StatusOK, `<svg><a href="/hidden">Hidden</a></svg>`
+	})
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(), WithExtractAssets(true))
+	assert.Nil(t, err)
+
+	report, err := crawler.Start(ctx, link, 3)
+	assert.Nil(t, err)
+
+	urls := report.URLs()
+	slices.Sort(urls)
+	assert.Equal(t, urls, []string{link, link + "/about"})
+	assert.Equal(t, crawler.VisitedCount(), 2)
+
+	contents, err := crawler.readPage(alphanumericRegex.ReplaceAllString(link+"/logo.svg", "_"))
+	assert.Nil(t, err)
+	assert.Equal(t, string(contents), `<svg><a href="/hidden">Hidden</a></svg>`)
+}
+
+func TestCrawler_DownloadAssetsRobots(t *testing.T) {
+	var (
+		link        = "http://localhost.com"
+		httpClient  = testutil.NewTestHttpClient()
+		privateHits atomic.Int32
+		publicHits  atomic.Int32
+	)
+
+	httpClient.Request(link+"/robots.txt", func() (int, string) {
+		return http.StatusOK, "User-agent: *\nDisallow: /private/\n"
+	})
+
+	httpClient.Request(link, func() (int, string) {
+		return http.StatusOK, `<img src="/private/a.png"><img src="/public/b.png">`
+	})
+
+	httpClient.Request(link+"/private/a.png", func() (int, string) {
+		privateHits.Add(1)
+		return http.StatusOK, "a"
+	})
+
+	httpClient.Request(link+"/public/b.png", func() (int, string) {
+		publicHits.Add(1)
+		return http.StatusOK, "b"
+	})
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(),
+		WithExtractAssets(true),
+		WithRobots(RobotsConfig{Respect: true}),
+	)
+	assert.Nil(t, err)
+
+	_, err = crawler.Start(context.Background(), link, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, privateHits.Load(), int32(0))
+	assert.Equal(t, publicHits.Load(), int32(1))
+}
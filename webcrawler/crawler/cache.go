@@ -0,0 +1,104 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+)
+
+// cacheMetadataFile is the storage key of the sidecar file, stored alongside the cached pages,
+// that holds the validators of each cached page.
+const cacheMetadataFile = "cache_meta.json"
+
+// errNotModified is returned by a conditional download when the server responds with
+// 304 Not Modified.
+var errNotModified = errors.New("not modified")
+
+// CacheEntry holds the HTTP validators of a cached page, used to make conditional requests.
+type CacheEntry struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// empty reports whether the entry has no validators.
+func (e CacheEntry) empty() bool {
+	return e.ETag == "" && e.LastModified == ""
+}
+
+// setConditionalHeaders adds the If-None-Match and If-Modified-Since headers for the entry.
+func (e CacheEntry) setConditionalHeaders(req *http.Request) {
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+// cacheEntry returns the validators stored for rawURL.
+func (c *Crawler) cacheEntry(rawURL string) (CacheEntry, bool) {
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
+	entry, ok := c.cacheMetadata[rawURL]
+	return entry, ok
+}
+
+// updateCacheEntry stores the validators of a response for rawURL.
+func (c *Crawler) updateCacheEntry(rawURL string, header http.Header) {
+	entry := CacheEntry{
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+	}
+
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
+	if entry.empty() {
+		delete(c.cacheMetadata, rawURL)
+		return
+	}
+
+	c.cacheMetadata[rawURL] = entry
+}
+
+// loadCacheMetadata reads the cache metadata sidecar from storage. A missing sidecar is not an error.
+func (c *Crawler) loadCacheMetadata() error {
+	data, err := c.readPage(cacheMetadataFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read cache metadata: %w", err)
+	}
+
+	c.cacheMu.Lock()
+	defer c.cacheMu.Unlock()
+
+	if err := json.Unmarshal(data, &c.cacheMetadata); err != nil {
+		return fmt.Errorf("unmarshal cache metadata: %w", err)
+	}
+
+	return nil
+}
+
+// saveCacheMetadata writes the cache metadata sidecar to storage.
+func (c *Crawler) saveCacheMetadata() error {
+	c.cacheMu.Lock()
+	data, err := json.Marshal(c.cacheMetadata)
+	c.cacheMu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("marshal cache metadata: %w", err)
+	}
+
+	if err := c.storage.Write(cacheMetadataFile, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("write cache metadata: %w", err)
+	}
+
+	return nil
+}
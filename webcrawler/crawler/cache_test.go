@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"context"
+	"kitchen/pkg/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCrawler_ConditionalRequests(t *testing.T) {
+	var (
+		ctx          = context.Background()
+		dir          = t.TempDir()
+		lastModified = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+		requests     int
+		notModified  int
+		body         = "<p>version 1</p>"
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") == lastModified {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	crawler, err := NewCrawler(nil, dir)
+	assert.Nil(t, err)
+
+	_, err = crawler.Start(ctx, server.URL, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, requests, 1)
+
+	_, err = os.Stat(filepath.Join(dir, cacheMetadataFile))
+	assert.Nil(t, err)
+
+	t.Run("not modified reuses the cached file", func(t *testing.T) {
+		// A full response would now overwrite the cached file with a different body
+		body = "<p>version 2</p>"
+
+		crawler, err := NewCrawler(nil, dir)
+		assert.Nil(t, err)
+
+		entry, ok := crawler.cacheEntry(server.URL)
+		assert.True(t, ok)
+		assert.Equal(t, entry, CacheEntry{ETag: `"v1"`, LastModified: lastModified})
+
+		report, err := crawler.Start(ctx, server.URL, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, requests, 2)
+		assert.Equal(t, notModified, 1)
+		assert.Equal(t, len(report.VisitedURLs), 1)
+		assert.Equal(t, report.VisitedURLs[0].ContentLength, int64(len("<p>version 1</p>")))
+
+		contents, err := os.ReadFile(filepath.Join(dir, alphanumericRegex.ReplaceAllString(server.URL, "_")))
+		assert.Nil(t, err)
+		assert.Equal(t, string(contents), "<p>version 1</p>")
+	})
+}
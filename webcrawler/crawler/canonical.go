@@ -0,0 +1,84 @@
+package crawler
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// defaultPorts maps URL schemes to the port used when a URL does not include one.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// CanonicalizeURL returns the canonical form of rawURL so that URLs that refer to the same
+// page compare equal. The scheme and host are lowercased, the default port of the scheme
+// is removed, trailing slashes are stripped from the path unless the path is the root,
+// and empty query strings and fragments are removed. An empty path becomes "/".
+func CanonicalizeURL(rawURL string) (string, error) {
+	uri, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("parse url: %w", err)
+	}
+
+	return canonicalize(uri), nil
+}
+
+// canonicalize returns the canonical form of uri as described by CanonicalizeURL.
+func canonicalize(uri *url.URL) string {
+	canonical := *uri
+	canonical.Scheme = strings.ToLower(canonical.Scheme)
+
+	if canonical.Host != "" {
+		hostname := strings.ToLower(canonical.Hostname())
+		port := canonical.Port()
+
+		if port == defaultPorts[canonical.Scheme] {
+			port = ""
+		}
+
+		switch {
+		case port != "":
+			canonical.Host = net.JoinHostPort(hostname, port)
+		case strings.Contains(hostname, ":"):
+			canonical.Host = "[" + hostname + "]"
+		default:
+			canonical.Host = hostname
+		}
+
+		canonical.Path = trimTrailingSlashes(canonical.Path)
+		canonical.RawPath = trimTrailingSlashes(canonical.RawPath)
+
+		if canonical.RawPath == "/" {
+			canonical.RawPath = ""
+		}
+	}
+
+	canonical.ForceQuery = false
+	canonical.Fragment = ""
+	canonical.RawFragment = ""
+
+	return canonical.String()
+}
+
+// visitKey returns the key rawURL is recorded under in the visited pages: its canonical
+// form, or rawURL itself when it cannot be parsed.
+func visitKey(rawURL string) string {
+	canonical, err := CanonicalizeURL(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return canonical
+}
+
+// trimTrailingSlashes removes the trailing slashes of path, keeping the root as "/".
+func trimTrailingSlashes(path string) string {
+	if path = strings.TrimRight(path, "/"); path == "" {
+		return "/"
+	}
+
+	return path
+}
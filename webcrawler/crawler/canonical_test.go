@@ -0,0 +1,79 @@
+package crawler
+
+import (
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalizeURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawURL  string
+		want    string
+		wantErr bool
+	}{
+		{name: "lowercases scheme and host", rawURL: "HTTP://Example.COM/Path", want: "http://example.com/Path"},
+		{name: "strips trailing slash", rawURL: "http://example.com/path/", want: "http://example.com/path"},
+		{name: "strips trailing slashes", rawURL: "http://example.com/path//", want: "http://example.com/path"},
+		{name: "keeps root path", rawURL: "http://example.com/", want: "http://example.com/"},
+		{name: "adds root path", rawURL: "http://example.com", want: "http://example.com/"},
+		{name: "strips empty query", rawURL: "http://example.com/path/?", want: "http://example.com/path"},
+		{name: "keeps query", rawURL: "http://example.com/path/?page=2", want: "http://example.com/path?page=2"},
+		{name: "strips fragment", rawURL: "http://example.com/path#section", want: "http://example.com/path"},
+		{name: "strips default http port", rawURL: "http://example.com:80/path", want: "http://example.com/path"},
+		{name: "strips default https port", rawURL: "https://example.com:443/path", want: "https://example.com/path"},
+		{name: "keeps other ports", rawURL: "http://example.com:8080/path", want: "http://example.com:8080/path"},
+		{name: "keeps https port on http", rawURL: "http://example.com:443/path", want: "http://example.com:443/path"},
+		{name: "handles ipv6 hosts", rawURL: "http://[::1]:80/path/", want: "http://[::1]/path"},
+		{name: "keeps escaped paths", rawURL: "http://example.com/a%2Fb/", want: "http://example.com/a%2Fb"},
+		{name: "rejects invalid urls", rawURL: "http://example.com/%zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalizeURL(tt.rawURL)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, got, tt.want)
+		})
+	}
+
+	t.Run("equivalent urls are identical", func(t *testing.T) {
+		var canonical []string
+
+		for _, rawURL := range []string{"HTTP://Example.COM/path/", "http://example.com/path/?", "http://example.com/path"} {
+			got, err := CanonicalizeURL(rawURL)
+			assert.Nil(t, err)
+
+			canonical = append(canonical, got)
+		}
+
+		assert.Equal(t, canonical, []string{"http://example.com/path", "http://example.com/path", "http://example.com/path"})
+	})
+}
+
+func TestCrawler_FindLinksCanonical(t *testing.T) {
+	uri, err := url.Parse("http://localhost.com")
+	assert.Nil(t, err)
+
+	page := `
+		<a href="/about/">About</a>
+		<a href="HTTP://LOCALHOST.COM:80/about#team">Team</a>
+		<a href="/">Home</a>`
+
+	crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir())
+	assert.Nil(t, err)
+
+	links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+	assert.Equal(t, links, []string{"http://localhost.com/about"})
+
+	assert.True(t, crawler.shouldVisit("http://localhost.com/about/", true))
+	assert.False(t, crawler.shouldVisit("HTTP://localhost.com/about?", true))
+}
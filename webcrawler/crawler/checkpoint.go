@@ -0,0 +1,113 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultCheckpointInterval is how often a checkpoint is saved during a crawl when
+// no interval is configured.
+const DefaultCheckpointInterval = 30 * time.Second
+
+// checkpoint is the serialized state of a crawl.
+type checkpoint struct {
+	// Visited holds the URLs whose pages were fetched.
+	Visited []string `json:"visited"`
+	// Frontier holds the URLs that were discovered but not yet fetched.
+	Frontier []frontierItem `json:"frontier"`
+}
+
+// frontierItem is a pending URL and the depth it should be crawled with.
+type frontierItem struct {
+	URL   string `json:"url"`
+	Depth int    `json:"depth"`
+}
+
+// SaveCheckpoint writes the fetched URLs and the pending frontier to a JSON file at path.
+// The file is written with writeFileAtomic.
+func (c *Crawler) SaveCheckpoint(path string) error {
+	c.mu.RLock()
+
+	cp := checkpoint{
+		Visited:  make([]string, 0, len(c.completed)),
+		Frontier: make([]frontierItem, 0, len(c.frontier)),
+	}
+
+	for link := range c.completed {
+		cp.Visited = append(cp.Visited, link)
+	}
+
+	for link, depth := range c.frontier {
+		cp.Frontier = append(cp.Frontier, frontierItem{URL: link, Depth: depth})
+	}
+
+	c.mu.RUnlock()
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+
+	if err := writeFileAtomic(path, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// LoadCheckpoint restores the state saved by SaveCheckpoint. Fetched URLs are marked as
+// visited so they are not fetched again and the frontier is crawled by the next Start.
+func (c *Crawler) LoadCheckpoint(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read checkpoint: %w", err)
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return fmt.Errorf("unmarshal checkpoint: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, link := range cp.Visited {
+		c.visitedPages[visitKey(link)] = struct{}{}
+		c.completed[link] = struct{}{}
+		delete(c.frontier, link)
+	}
+
+	for _, item := range cp.Frontier {
+		if _, done := c.completed[item.URL]; done {
+			continue
+		}
+
+		if item.Depth > c.frontier[item.URL] {
+			c.frontier[item.URL] = item.Depth
+		}
+	}
+
+	return nil
+}
+
+// saveCheckpointPeriodically saves a checkpoint to c.checkpointPath every
+// c.checkpointInterval until ctx is done.
+func (c *Crawler) saveCheckpointPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(c.checkpointInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.SaveCheckpoint(c.checkpointPath); err != nil {
+				c.logger.Error("failed to save checkpoint", "path", c.checkpointPath, "error", err)
+			}
+		}
+	}
+}
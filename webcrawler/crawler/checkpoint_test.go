@@ -0,0 +1,144 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// checkpointFrontier returns the frontier saved in the checkpoint at path.
+func checkpointFrontier(t *testing.T, path string) []frontierItem {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+
+	var cp struct {
+		Frontier []frontierItem `json:"frontier"`
+	}
+	assert.Nil(t, json.Unmarshal(data, &cp))
+
+	return cp.Frontier
+}
+
+func TestCrawler_Checkpoint(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		checkpoint = filepath.Join(t.TempDir(), "checkpoint.json")
+
+		mu      sync.Mutex
+		fetches = make(map[string]int)
+		cancel  context.CancelFunc
+	)
+
+	page := func(path, body string) {
+		httpClient.Request(link+path, func() (code int, _ string) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			fetches[link+path]++
+
+			// Interrupt the first crawl right after the home page is fetched
+			if path == "" && cancel != nil {
+				cancel()
+			}
+
+			return http.StatusOK, body
+		})
+	}
+
+	page("", `<a href="/a">A</a><a href="/b">B</a>`)
+	page("/a", `<a href="/a/c">C</a>`)
+	page("/b", "")
+	page("/a/c", "")
+
+	t.Run("interrupted crawl saves a checkpoint", func(t *testing.T) {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+		defer cancel()
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithCheckpoint(checkpoint))
+		assert.Nil(t, err)
+
+		_, err = crawler.Start(ctx, link, 3)
+		assert.Nil(t, err)
+
+		_, err = os.Stat(checkpoint)
+		assert.Nil(t, err)
+		assert.Equal(t, fetches, map[string]int{link: 1})
+	})
+
+	t.Run("resumed crawl skips visited pages", func(t *testing.T) {
+		cancel = nil
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithCheckpoint(checkpoint))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(context.Background(), link, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 3)
+		assert.Equal(t, fetches, map[string]int{
+			link:          1,
+			link + "/a":   1,
+			link + "/b":   1,
+			link + "/a/c": 1,
+		})
+		assert.Equal(t, len(checkpointFrontier(t, checkpoint)), 0)
+	})
+
+	t.Run("load missing checkpoint", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		err = crawler.LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestCrawler_CheckpointFilters(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		checkpoint = filepath.Join(t.TempDir(), "checkpoint.json")
+	)
+
+	httpClient.Request(link, func() (int, string) {
+		return http.StatusOK, `<a href="/">Home</a><a href="/cdn/x">X</a>`
+	})
+
+	for _, path := range []string{"/b", "/cdn/big"} {
+		httpClient.Request(link+path, func() (int, string) {
+			return http.StatusOK, ""
+		})
+	}
+
+	data, err := json.Marshal(map[string]any{
+		"frontier": []frontierItem{{URL: link + "/b", Depth: 2}, {URL: link + "/cdn/big", Depth: 2}},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(checkpoint, data, 0o644))
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(),
+		WithCheckpoint(checkpoint),
+		WithExcludePattern("/cdn/"),
+	)
+	assert.Nil(t, err)
+
+	report, err := crawler.Start(context.Background(), link, 2)
+	assert.Nil(t, err)
+
+	urls := report.URLs()
+	slices.Sort(urls)
+	assert.Equal(t, urls, []string{link, link + "/b"})
+
+	// Excluded URLs and URLs visited under their canonical form are not kept
+	assert.Equal(t, len(checkpointFrontier(t, checkpoint)), 0)
+}
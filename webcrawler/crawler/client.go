@@ -0,0 +1,169 @@
+package crawler
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// TransportConfig sets the timeouts of the phases of a request made by the default HTTP
+// client. A zero timeout keeps the default of http.DefaultTransport.
+type TransportConfig struct {
+	// DialTimeout limits the time to establish a connection, including DNS resolution.
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout limits the time of the TLS handshake.
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout limits the time to wait for the response headers after the
+	// request is written.
+	ResponseHeaderTimeout time.Duration
+	// ExpectContinueTimeout limits the time to wait for the first response headers of a
+	// request with an "Expect: 100-continue" header.
+	ExpectContinueTimeout time.Duration
+}
+
+// apply sets the timeouts of the configuration on transport.
+func (tc TransportConfig) apply(transport *http.Transport) {
+	if tc.DialTimeout > 0 {
+		transport.DialContext = (&net.Dialer{
+			Timeout:   tc.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+
+	if tc.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = tc.TLSHandshakeTimeout
+	}
+
+	if tc.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = tc.ResponseHeaderTimeout
+	}
+
+	if tc.ExpectContinueTimeout > 0 {
+		transport.ExpectContinueTimeout = tc.ExpectContinueTimeout
+	}
+}
+
+// newTransport returns a clone of http.DefaultTransport configured with the crawler's
+// transport options.
+func (c *Crawler) newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if c.proxy != nil {
+		transport.Proxy = c.proxy
+	}
+
+	if c.tlsConfig != nil {
+		transport.TLSClientConfig = c.tlsConfig
+	}
+
+	if c.transportConfig != nil {
+		c.transportConfig.apply(transport)
+	}
+
+	if c.maxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = c.maxConnsPerHost
+	}
+
+	if c.maxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = c.maxIdleConnsPerHost
+	}
+
+	if c.idleConnTimeout > 0 {
+		transport.IdleConnTimeout = c.idleConnTimeout
+	}
+
+	return transport
+}
+
+// warnIgnoredTransportOptions logs the transport options that have no effect because a
+// custom HTTP client is used.
+func (c *Crawler) warnIgnoredTransportOptions() {
+	if c.proxy != nil {
+		c.logger.Warn("proxy is ignored when a custom http client is used")
+	}
+
+	if c.tlsConfig != nil {
+		c.logger.Warn("tls options are ignored when a custom http client is used")
+	}
+
+	if c.transportConfig != nil {
+		c.logger.Warn("transport config is ignored when a custom http client is used")
+	}
+
+	if c.maxConnsPerHost > 0 || c.maxIdleConnsPerHost > 0 || c.idleConnTimeout > 0 {
+		c.logger.Warn("connection pool options are ignored when a custom http client is used")
+	}
+}
+
+// cookieClient is an HttpClient that sends the cookies stored in a jar with each
+// request and stores the cookies set by each response.
+type cookieClient struct {
+	client HttpClient
+	jar    http.CookieJar
+}
+
+// Do adds the jar's cookies for the request URL, performs the request and saves any
+// cookies returned by the response.
+func (c *cookieClient) Do(req *http.Request) (*http.Response, error) {
+	for _, cookie := range c.jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if cookies := resp.Cookies(); len(cookies) > 0 {
+		c.jar.SetCookies(req.URL, cookies)
+	}
+
+	return resp, nil
+}
+
+// directorClient is an HttpClient that passes each request to a director function, which
+// may modify it, before performing it.
+type directorClient struct {
+	client   HttpClient
+	director func(req *http.Request)
+}
+
+// Do applies the director to req and performs it.
+func (c *directorClient) Do(req *http.Request) (*http.Response, error) {
+	c.director(req)
+	return c.client.Do(req)
+}
+
+// withHeaders wraps client so that each request is sent with the given headers. Headers
+// already set on a request are replaced.
+func withHeaders(client HttpClient, header http.Header) HttpClient {
+	return &directorClient{
+		client: client,
+		director: func(req *http.Request) {
+			for key, values := range header {
+				req.Header[key] = values
+			}
+		},
+	}
+}
+
+// setHeader sets a header sent with every request.
+func (c *Crawler) setHeader(key, value string) {
+	if c.headers == nil {
+		c.headers = make(http.Header)
+	}
+
+	c.headers.Set(key, value)
+}
+
+// withCookieJar attaches jar to client. The jar is set directly on an *http.Client so
+// that cookies set during redirects are kept; any other client is wrapped.
+func withCookieJar(client HttpClient, jar http.CookieJar) HttpClient {
+	if httpClient, ok := client.(*http.Client); ok && httpClient.Jar == nil {
+		clone := *httpClient
+		clone.Jar = jar
+		return &clone
+	}
+
+	return &cookieClient{client: client, jar: jar}
+}
@@ -0,0 +1,411 @@
+package crawler
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"kitchen/pkg/assert"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newSessionServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret", Path: "/"})
+		_, _ = w.Write([]byte(`<a href="/dashboard">Dashboard</a>`))
+	})
+	mux.HandleFunc("/dashboard", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != "secret" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("welcome"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestWithCookieJar(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		client HttpClient
+	}{
+		{name: "default client"},
+		{name: "custom client", client: &testClient{client: http.DefaultClient}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSessionServer(t)
+
+			crawler, err := NewCrawler(tt.client, t.TempDir(), WithCookieJar(nil))
+			assert.Nil(t, err)
+
+			_, err = crawler.Fetch(ctx, server.URL+"/login")
+			assert.Nil(t, err)
+
+			_, err = crawler.Fetch(ctx, server.URL+"/dashboard")
+			assert.Nil(t, err)
+		})
+	}
+
+	t.Run("without a jar the session is lost", func(t *testing.T) {
+		server := newSessionServer(t)
+
+		crawler, err := NewCrawler(nil, t.TempDir())
+		assert.Nil(t, err)
+
+		_, err = crawler.Fetch(ctx, server.URL+"/login")
+		assert.Nil(t, err)
+
+		_, err = crawler.Fetch(ctx, server.URL+"/dashboard")
+		assert.NotNil(t, err)
+	})
+}
+
+// testClient is an HttpClient that is not an *http.Client.
+type testClient struct {
+	client *http.Client
+}
+
+func (c *testClient) Do(req *http.Request) (*http.Response, error) {
+	return c.client.Do(req)
+}
+
+func TestWithProxy(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.String())
+		mu.Unlock()
+
+		if r.Method == http.MethodConnect {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/about":
+			_, _ = w.Write([]byte("<p>About</p>"))
+		default:
+			_, _ = w.Write([]byte(`<a href="/about">About</a>`))
+		}
+	}))
+	t.Cleanup(proxy.Close)
+
+	ctx := context.Background()
+
+	t.Run("sends requests through the proxy", func(t *testing.T) {
+		mu.Lock()
+		requests = nil
+		mu.Unlock()
+
+		crawler, err := New(WithDestinationDir(t.TempDir()), WithProxy(proxy.URL))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, "http://crawler.test", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 2)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		slices.Sort(requests)
+		assert.Equal(t, requests, []string{"GET http://crawler.test/", "GET http://crawler.test/about"})
+	})
+
+	t.Run("tunnels https requests through the proxy", func(t *testing.T) {
+		mu.Lock()
+		requests = nil
+		mu.Unlock()
+
+		crawler, err := New(WithDestinationDir(t.TempDir()), WithProxy(proxy.URL))
+		assert.Nil(t, err)
+
+		_, err = crawler.Fetch(ctx, "https://crawler.test")
+		assert.NotNil(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, requests, []string{"CONNECT //crawler.test:443"})
+	})
+
+	t.Run("rejects invalid proxies", func(t *testing.T) {
+		for _, proxyURL := range []string{"ftp://proxy.test", "http://", "://proxy"} {
+			_, err := New(WithDestinationDir(t.TempDir()), WithProxy(proxyURL))
+			assert.NotNil(t, err)
+		}
+	})
+
+	t.Run("accepts supported schemes", func(t *testing.T) {
+		for _, proxyURL := range []string{"", "http://proxy.test:8080", "https://proxy.test", "socks5://proxy.test:1080"} {
+			crawler, err := New(WithDestinationDir(t.TempDir()), WithProxy(proxyURL))
+			assert.Nil(t, err)
+			assert.NotNil(t, crawler.proxy)
+		}
+	})
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<p>Secure</p>"))
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	t.Run("rejects self-signed certificates by default", func(t *testing.T) {
+		crawler, err := New(WithDestinationDir(t.TempDir()))
+		assert.Nil(t, err)
+
+		_, err = crawler.Fetch(ctx, server.URL)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("skips certificate verification", func(t *testing.T) {
+		crawler, err := New(WithDestinationDir(t.TempDir()), WithInsecureSkipVerify(true))
+		assert.Nil(t, err)
+
+		_, err = crawler.Fetch(ctx, server.URL)
+		assert.Nil(t, err)
+	})
+
+	t.Run("uses the tls config", func(t *testing.T) {
+		pool := x509.NewCertPool()
+		pool.AddCert(server.Certificate())
+
+		crawler, err := New(WithDestinationDir(t.TempDir()), WithTLSConfig(&tls.Config{RootCAs: pool}))
+		assert.Nil(t, err)
+
+		_, err = crawler.Fetch(ctx, server.URL)
+		assert.Nil(t, err)
+	})
+
+	t.Run("warns when a custom client is used", func(t *testing.T) {
+		handler := &recordHandler{}
+
+		_, err := New(
+			WithDestinationDir(t.TempDir()),
+			WithHTTPClient(server.Client()),
+			WithInsecureSkipVerify(true),
+			WithLogger(slog.New(handler)),
+		)
+		assert.Nil(t, err)
+
+		record, ok := handler.find("tls options are ignored when a custom http client is used")
+		assert.True(t, ok)
+		assert.Equal(t, record.Level, slog.LevelWarn)
+	})
+}
+
+func TestWithTransportConfig(t *testing.T) {
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+
+		_, _ = w.Write([]byte("<p>Slow</p>"))
+	}))
+	t.Cleanup(func() {
+		close(release)
+		server.Close()
+	})
+
+	ctx := context.Background()
+
+	t.Run("fails when the response header timeout is exceeded", func(t *testing.T) {
+		crawler, err := New(
+			WithDestinationDir(t.TempDir()),
+			WithTransportConfig(TransportConfig{ResponseHeaderTimeout: 50 * time.Millisecond}),
+		)
+		assert.Nil(t, err)
+
+		transport, ok := crawler.httpClient.(*http.Client).Transport.(*http.Transport)
+		assert.True(t, ok)
+		assert.Equal(t, transport.ResponseHeaderTimeout, 50*time.Millisecond)
+
+		_, err = crawler.Fetch(ctx, server.URL)
+		assert.NotNil(t, err)
+		assert.MatchesRegexp(t, err.Error(), "timeout awaiting response headers")
+	})
+
+	t.Run("keeps default timeouts", func(t *testing.T) {
+		crawler, err := New(
+			WithDestinationDir(t.TempDir()),
+			WithTransportConfig(TransportConfig{DialTimeout: time.Second}),
+		)
+		assert.Nil(t, err)
+
+		transport := crawler.httpClient.(*http.Client).Transport.(*http.Transport)
+		assert.Equal(t, transport.TLSHandshakeTimeout, http.DefaultTransport.(*http.Transport).TLSHandshakeTimeout)
+		assert.Equal(t, transport.ResponseHeaderTimeout, time.Duration(0))
+	})
+
+	t.Run("warns when a custom client is used", func(t *testing.T) {
+		handler := &recordHandler{}
+
+		_, err := New(
+			WithDestinationDir(t.TempDir()),
+			WithHTTPClient(server.Client()),
+			WithTransportConfig(TransportConfig{DialTimeout: time.Second}),
+			WithLogger(slog.New(handler)),
+		)
+		assert.Nil(t, err)
+
+		_, ok := handler.find("transport config is ignored when a custom http client is used")
+		assert.True(t, ok)
+	})
+}
+
+func TestWithMaxConnsPerHost(t *testing.T) {
+	const maxConns = 2
+
+	var (
+		mu          sync.Mutex
+		conns, peak int
+		home        strings.Builder
+	)
+
+	for i := range 10 {
+		fmt.Fprintf(&home, `<a href="/page-%d">Page %d</a>`, i, i)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			time.Sleep(20 * time.Millisecond)
+			_, _ = w.Write([]byte("<p>Page</p>"))
+			return
+		}
+
+		_, _ = w.Write([]byte(home.String()))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch state {
+		case http.StateNew:
+			conns++
+			peak = max(peak, conns)
+		case http.StateClosed, http.StateHijacked:
+			conns--
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	t.Run("limits concurrent connections to a host", func(t *testing.T) {
+		crawler, err := New(
+			WithDestinationDir(t.TempDir()),
+			WithMaxConcurrent(8),
+			WithMaxConnsPerHost(maxConns),
+		)
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, server.URL, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 11)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.True(t, peak <= maxConns)
+	})
+
+	t.Run("configures the connection pool", func(t *testing.T) {
+		crawler, err := New(
+			WithDestinationDir(t.TempDir()),
+			WithMaxConnsPerHost(4),
+			WithMaxIdleConnsPerHost(3),
+			WithIdleConnTimeout(time.Minute),
+		)
+		assert.Nil(t, err)
+
+		transport := crawler.httpClient.(*http.Client).Transport.(*http.Transport)
+		assert.Equal(t, transport.MaxConnsPerHost, 4)
+		assert.Equal(t, transport.MaxIdleConnsPerHost, 3)
+		assert.Equal(t, transport.IdleConnTimeout, time.Minute)
+	})
+
+	t.Run("warns when a custom client is used", func(t *testing.T) {
+		handler := &recordHandler{}
+
+		_, err := New(
+			WithDestinationDir(t.TempDir()),
+			WithHTTPClient(server.Client()),
+			WithMaxConnsPerHost(maxConns),
+			WithLogger(slog.New(handler)),
+		)
+		assert.Nil(t, err)
+
+		_, ok := handler.find("connection pool options are ignored when a custom http client is used")
+		assert.True(t, ok)
+	})
+}
+
+func TestWithAcceptHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "<p>%s|%s</p>", r.Header.Get("Accept-Language"), r.Header.Get("Accept-Encoding"))
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		client HttpClient
+	}{
+		{name: "default client"},
+		{name: "custom client", client: server.Client()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crawler, err := NewCrawler(tt.client, t.TempDir(),
+				WithAcceptLanguage("sw, en;q=0.8"),
+				WithAcceptEncoding("identity"),
+			)
+			assert.Nil(t, err)
+
+			buffer, err := crawler.DownloadAndSave(ctx, server.URL, "page")
+			assert.Nil(t, err)
+			assert.Equal(t, buffer.String(), "<p>sw, en;q=0.8|identity</p>")
+		})
+	}
+
+	t.Run("sends no headers by default", func(t *testing.T) {
+		crawler, err := NewCrawler(server.Client(), t.TempDir())
+		assert.Nil(t, err)
+
+		buffer, err := crawler.DownloadAndSave(ctx, server.URL, "page")
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(buffer.String(), "<p>|"))
+	})
+}
@@ -0,0 +1,65 @@
+package crawler
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// supportedEncodings are the content codings that decodeBody can decompress.
+var supportedEncodings = []string{"gzip", "x-gzip", "deflate", "identity"}
+
+// countingReader is an io.Reader that counts the bytes read from the underlying reader.
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+// Read implements io.Reader.
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// decodeBody returns a reader that decompresses body according to the Content-Encoding
+// of its response. Bodies with no or an unsupported encoding are returned as they are.
+//
+// The deflate encoding is expected to be zlib-wrapped as specified, but raw deflate data,
+// which some servers send instead, is accepted as well.
+func decodeBody(body io.Reader, contentEncoding string) (io.Reader, error) {
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("create gzip reader: %w", err)
+		}
+
+		return reader, nil
+	case "deflate":
+		buffered := bufio.NewReader(body)
+
+		if header, _ := buffered.Peek(2); len(header) < 2 || !isZlibHeader(header[0], header[1]) {
+			return flate.NewReader(buffered), nil
+		}
+
+		reader, err := zlib.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("create zlib reader: %w", err)
+		}
+
+		return reader, nil
+	}
+
+	return body, nil
+}
+
+// isZlibHeader reports whether the two bytes are a valid zlib header: the deflate
+// compression method and a checksum that is a multiple of 31.
+func isZlibHeader(cmf, flg byte) bool {
+	return cmf&0x0f == 8 && (uint16(cmf)<<8|uint16(flg))%31 == 0
+}
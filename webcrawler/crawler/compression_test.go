@@ -0,0 +1,97 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"kitchen/pkg/assert"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestWithRequestCompression(t *testing.T) {
+	const page = `<a href="/about">About</a><a href="/contact">Contact</a>`
+
+	compress := func(t *testing.T, w io.WriteCloser) {
+		t.Helper()
+
+		_, err := w.Write([]byte(page))
+		assert.Nil(t, err)
+		assert.Nil(t, w.Close())
+	}
+
+	var gzipped, zlibbed, deflated bytes.Buffer
+
+	compress(t, gzip.NewWriter(&gzipped))
+	compress(t, zlib.NewWriter(&zlibbed))
+
+	flateWriter, err := flate.NewWriter(&deflated, flate.DefaultCompression)
+	assert.Nil(t, err)
+	compress(t, flateWriter)
+
+	var acceptEncoding string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+
+		switch r.URL.Query().Get("encoding") {
+		case "gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			_, _ = w.Write(gzipped.Bytes())
+		case "zlib":
+			w.Header().Set("Content-Encoding", "deflate")
+			_, _ = w.Write(zlibbed.Bytes())
+		case "deflate":
+			w.Header().Set("Content-Encoding", "deflate")
+			_, _ = w.Write(deflated.Bytes())
+		default:
+			_, _ = w.Write([]byte(page))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name  string
+		path  string
+		bytes int
+	}{
+		{name: "gzip", path: "/?encoding=gzip", bytes: gzipped.Len()},
+		{name: "zlib deflate", path: "/?encoding=zlib", bytes: zlibbed.Len()},
+		{name: "raw deflate", path: "/?encoding=deflate", bytes: deflated.Len()},
+		{name: "identity", path: "/", bytes: len(page)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crawler, err := New(WithDestinationDir(t.TempDir()), WithRequestCompression(true))
+			assert.Nil(t, err)
+
+			links, err := crawler.Fetch(context.Background(), server.URL+tt.path)
+			assert.Nil(t, err)
+			assert.Equal(t, acceptEncoding, "gzip, deflate")
+
+			slices.Sort(links)
+			assert.Equal(t, links, []string{server.URL + "/about", server.URL + "/contact"})
+			assert.Equal(t, crawler.BytesDownloaded(), int64(tt.bytes))
+		})
+	}
+
+	t.Run("accepts supported encodings with quality values", func(t *testing.T) {
+		_, err := New(WithDestinationDir(t.TempDir()), WithAcceptEncoding("gzip;q=1.0, Deflate, identity;q=0.5"))
+		assert.Nil(t, err)
+	})
+
+	t.Run("decompresses unrequested encodings", func(t *testing.T) {
+		crawler, err := New(WithDestinationDir(t.TempDir()), WithAcceptEncoding("identity"))
+		assert.Nil(t, err)
+
+		buffer, err := crawler.DownloadAndSave(context.Background(), server.URL+"/?encoding=gzip", "page")
+		assert.Nil(t, err)
+		assert.Equal(t, buffer.String(), page)
+	})
+}
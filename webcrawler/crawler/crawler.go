@@ -3,22 +3,27 @@ package crawler
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"maps"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/atom"
+	"golang.org/x/time/rate"
 
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
-	"path/filepath"
 	"regexp"
 )
 
@@ -29,9 +34,17 @@ var alphanumericRegex = regexp.MustCompile(`[^a-zA-Z0-9]+`)
 // DestinationDir is the default directory where fetched pages will be saved.
 const DestinationDir = "storage"
 
+// DefaultTimeout is the request timeout used by the default HTTP client.
+const DefaultTimeout = 30 * time.Second
+
 // ErrPageNotFound is returned when an HTTP request returns a 404 status code.
+// The returned error is a *StatusError that matches ErrPageNotFound with errors.Is.
 var ErrPageNotFound = errors.New("page not found")
 
+// ErrByteLimitReached is returned when a download is refused because the crawler has
+// already downloaded the maximum number of bytes.
+var ErrByteLimitReached = errors.New("byte limit reached")
+
 // HttpClient defines the interface for making HTTP requests.
 type HttpClient interface {
 	Do(req *http.Request) (*http.Response, error)
@@ -47,18 +60,140 @@ type Crawler struct {
 	mu             sync.RWMutex
 	httpClient     HttpClient
 	destinationDir string
+	storage        Storage
+	fileNamer      FileNamer
+	urlLocksMu     sync.Mutex
+	urlLocks       map[string]*keyLock
+	memoryOnly     bool
 	visitedPages   map[string]struct{}
+	claimedAssets  map[string]struct{}
+	completed      map[string]struct{}
+	frontier       map[string]int
 	maxConcurrent  int
+	semaphore      chan struct{}
+	traversal      TraversalStrategy
+	maxDepth       int
+	results        []PageResult
+	crawlErrors    []CrawlError
+	brokenLinks    []BrokenLink
+	sources        map[string]string
+
+	bytesDownloaded atomic.Int64
+	errorCount      atomic.Int64
+	timeout         time.Duration
+	crawlTimeout    time.Duration
+	userAgent       string
+	cookieJar       http.CookieJar
+
+	fetchSitemap       bool
+	checkpointPath     string
+	checkpointInterval time.Duration
+	manifestPath       string
+	rateLimiter        *DomainRateLimiter
+	onPageFetched      func(PageResult)
+	onLinkFound        func(from, to string)
+	includePatterns    []*regexp.Regexp
+	excludePatterns    []*regexp.Regexp
+	domainWhitelist    map[string]struct{}
+	domainBlacklist    map[string]struct{}
+	linkGraph          *LinkGraph
+	followMetaRefresh  bool
+	respectNoFollow    bool
+	useCanonical       bool
+	maxPages           int
+	pageCount          int
+	pageLimitReached   bool
+	maxBytes           int64
+	byteLimitReached   atomic.Bool
+	running            atomic.Bool
+	resetClearCache    bool
+	deduplicateContent bool
+	logger             *slog.Logger
+	proxy              func(*http.Request) (*url.URL, error)
+	tlsConfig          *tls.Config
+	transportConfig    *TransportConfig
+	bandwidthLimiter   *rate.Limiter
+	headers            http.Header
+	extractText        bool
+	saveHeaders        bool
+	contentHashes      map[string]string
+	duplicates         map[string]string
+	progressCh         chan<- ProgressEvent
+	progressBufferSize int
+	progressEvents     chan ProgressEvent
+	startedAt          time.Time
+
+	extractAssets          bool
+	downloadExternalAssets bool
+	extractFormActions     bool
+	extractPostForms       bool
+	followPagination       bool
+	allowedSchemes         map[string]struct{}
+	maxURLLength           int
+
+	maxConnsPerHost     int
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+
+	pauseMu sync.Mutex
+	pauseCh chan struct{}
+
+	politenessDelay time.Duration
+	politenessMu    sync.Mutex
+	lastRequestTime map[string]time.Time
+
+	retryAttempts  int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+
+	cacheMu       sync.Mutex
+	cacheMetadata map[string]CacheEntry
+
+	robots      RobotsConfig
+	robotsMu    sync.Mutex
+	robotsCache map[string]*robotsEntry
 }
 
-// DownloadAndSave downloads the content from the given URI and saves it to the specified filename.
-// It returns a buffer containing the downloaded content for immediate use.
-func (c *Crawler) DownloadAndSave(ctx context.Context, uri string, filename string) (*bytes.Buffer, error) {
+// newRequest creates a GET request for uri with the crawler's default headers set.
+func (c *Crawler) newRequest(ctx context.Context, uri string) (*http.Request, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	return req, nil
+}
+
+// DownloadAndSave downloads the content from the given URI and saves it to the crawler's
+// storage under key. It returns a buffer containing the downloaded content for immediate use.
+//
+// When a maximum number of bytes is configured, ErrByteLimitReached is returned once that
+// many bytes have been downloaded. When content deduplication is enabled, a page with the
+// same content as one downloaded earlier from another URL is not saved. When headers are
+// saved, the response headers are stored in a sidecar next to the page.
+func (c *Crawler) DownloadAndSave(ctx context.Context, uri string, key string) (*bytes.Buffer, error) {
+	return c.download(ctx, uri, key, CacheEntry{})
+}
+
+// download is DownloadAndSave with a conditional request made for the validators in cached.
+// It returns errNotModified, leaving the stored page untouched, when the server responds with 304.
+func (c *Crawler) download(ctx context.Context, uri string, key string, cached CacheEntry) (*bytes.Buffer, error) {
+	if c.maxBytes > 0 && c.bytesDownloaded.Load() >= c.maxBytes {
+		c.byteLimitReached.Store(true)
+		return nil, ErrByteLimitReached
+	}
+
+	req, err := c.newRequest(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	cached.setConditionalHeaders(req)
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("do request: %w", err)
@@ -70,93 +205,374 @@ func (c *Crawler) DownloadAndSave(ctx context.Context, uri string, filename stri
 
 	switch resp.StatusCode {
 	case http.StatusOK:
-		file, err := os.Create(filename)
+		var buffer bytes.Buffer
+
+		// Bytes are counted as received, before they are decompressed
+		counter := &countingReader{reader: resp.Body}
+
+		var body io.Reader = counter
+		if c.bandwidthLimiter != nil {
+			body = NewThrottledReader(ctx, body, c.bandwidthLimiter)
+		}
+
+		body, err := decodeBody(body, resp.Header.Get("Content-Encoding"))
 		if err != nil {
-			return nil, fmt.Errorf("create file: %w", err)
+			c.bytesDownloaded.Add(counter.n)
+			return nil, fmt.Errorf("decode response: %w", err)
 		}
 
-		defer func(file *os.File) {
-			_ = file.Close()
-		}(file)
+		_, err = io.Copy(&buffer, body)
+		c.bytesDownloaded.Add(counter.n)
 
-		var buffer bytes.Buffer
-		writer := io.MultiWriter(file, &buffer)
+		if err != nil {
+			return nil, fmt.Errorf("read response: %w", err)
+		}
+
+		// Pages with the same content as a page downloaded earlier are not stored again;
+		// an alias to that page is stored instead
+		if c.deduplicateContent {
+			if first, duplicate := c.recordContent(uri, buffer.Bytes()); duplicate {
+				if err := c.saveAlias(key, first); err != nil {
+					return nil, err
+				}
+
+				return &buffer, nil
+			}
+		}
 
-		if _, err := io.Copy(writer, resp.Body); err != nil {
-			return nil, fmt.Errorf("copy response to file: %w", err)
+		if err := c.storage.Write(key, bytes.NewReader(buffer.Bytes())); err != nil {
+			return nil, fmt.Errorf("save page: %w", err)
 		}
 
-		// Seek to the beginning of the file for reading
-		if _, err = file.Seek(0, io.SeekStart); err != nil {
-			return nil, fmt.Errorf("seek file: %w", err)
+		if c.saveHeaders {
+			if err := c.writeHeaders(key, resp); err != nil {
+				return nil, fmt.Errorf("save headers: %w", err)
+			}
 		}
 
+		c.updateCacheEntry(uri, resp.Header)
+
 		return &buffer, nil
-	case http.StatusNotFound:
-		return nil, ErrPageNotFound
+	case http.StatusNotModified:
+		return nil, errNotModified
+	}
+
+	return nil, &StatusError{StatusCode: resp.StatusCode}
+}
+
+// inScope reports whether uri is a child of baseURL: it must be on the same host and
+// its path must start with the path of baseURL.
+func inScope(baseURL, uri *url.URL) bool {
+	return uri.Host == baseURL.Host && strings.HasPrefix(uri.Path, baseURL.Path)
+}
+
+// normalizeDomain lowercases a host name and strips its "www." prefix.
+func normalizeDomain(host string) string {
+	return strings.TrimPrefix(strings.ToLower(host), "www.")
+}
+
+// allowedScheme reports whether the scheme of uri is one of the allowed schemes.
+func (c *Crawler) allowedScheme(uri *url.URL) bool {
+	_, allowed := c.allowedSchemes[strings.ToLower(uri.Scheme)]
+	return allowed
+}
+
+// allowedLink reports whether a link found on baseURL may be followed. Links with a
+// scheme that is not allowed and links to blacklisted domains are always skipped. When
+// a whitelist is set, only whitelisted domains are followed; otherwise the link must be
+// a child of baseURL.
+func (c *Crawler) allowedLink(baseURL, uri *url.URL) bool {
+	if !c.allowedScheme(uri) {
+		return false
+	}
+
+	domain := normalizeDomain(uri.Hostname())
+
+	if _, blocked := c.domainBlacklist[domain]; blocked {
+		return false
+	}
+
+	if len(c.domainWhitelist) > 0 {
+		_, allowed := c.domainWhitelist[domain]
+		return allowed
 	}
 
-	return nil, fmt.Errorf("request failed with status: %d", resp.StatusCode)
+	return inScope(baseURL, uri)
+}
+
+// allowedAsset reports whether an asset used by baseURL may be downloaded. Assets with a
+// scheme that is not allowed and assets on blacklisted domains are always skipped. Assets
+// must be on the same host as baseURL or on a whitelisted domain, unless external assets
+// are downloaded.
+func (c *Crawler) allowedAsset(baseURL, uri *url.URL) bool {
+	if !c.allowedScheme(uri) {
+		return false
+	}
+
+	domain := normalizeDomain(uri.Hostname())
+
+	if _, blocked := c.domainBlacklist[domain]; blocked {
+		return false
+	}
+
+	if c.downloadExternalAssets || domain == normalizeDomain(baseURL.Hostname()) {
+		return true
+	}
+
+	_, allowed := c.domainWhitelist[domain]
+	return allowed
+}
+
+// attrValue returns the value of the attribute key of token.
+func attrValue(token html.Token, key string) (string, bool) {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return attr.Val, true
+		}
+	}
+
+	return "", false
+}
+
+// hasToken reports whether the space-separated list contains token, ignoring case.
+func hasToken(list, token string) bool {
+	return slices.ContainsFunc(strings.Fields(list), func(field string) bool {
+		return strings.EqualFold(field, token)
+	})
+}
+
+// metaRefreshURL extracts the target URL from the content of a <meta http-equiv="refresh">
+// tag, such as "0; url=/new-page".
+func metaRefreshURL(content string) (string, bool) {
+	_, target, ok := strings.Cut(content, ";")
+	if !ok {
+		return "", false
+	}
+
+	target = strings.TrimSpace(target)
+	if len(target) >= 4 && strings.EqualFold(target[:4], "url=") {
+		target = target[4:]
+	}
+
+	target = strings.Trim(strings.TrimSpace(target), `"'`)
+	return target, target != ""
+}
+
+// LinkSet holds the URLs found in an HTML document.
+type LinkSet struct {
+	// Navigation are the links to other pages.
+	Navigation []string
+	// Assets are the URLs of the images, scripts, stylesheets and media sources the
+	// document uses. They are only extracted when asset extraction is enabled.
+	Assets []string
+}
+
+// FindNavigationLinks extracts the links to other pages from an HTML document. It returns
+// the Navigation links of FindLinks.
+func (c *Crawler) FindNavigationLinks(baseURL *url.URL, reader io.Reader) []string {
+	return c.FindLinks(baseURL, reader).Navigation
 }
 
 // FindLinks extracts all valid links from an HTML document.
 //
-// It parses the HTML, finds all <a> tags with href attributes, and returns
-// a list of absolute URLs that belong to the same host as the base URI.
-func (c *Crawler) FindLinks(baseURL *url.URL, reader io.Reader) []string {
-	tokenizer := html.NewTokenizer(reader)
-	foundLinks := make(map[string]struct{})
+// It parses the HTML, finds all <a> and <area> tags with href attributes, and returns
+// as navigation links the absolute URLs in canonical form, see CanonicalizeURL, that belong
+// to the same host as the base URI, or to the whitelisted domains when a domain whitelist
+// is set.
+//
+// Relative links are resolved against the href of the first <base> tag when the
+// document has one, and against baseURL otherwise. When meta refresh following is
+// enabled, the targets of <meta http-equiv="refresh"> tags are included. When form action
+// extraction is enabled, the actions of GET forms are included, and the actions of POST
+// forms as well when POST form extraction is enabled. When pagination is followed, the
+// targets of <link rel="next"> and <link rel="prev"> tags are included.
+//
+// When nofollow is respected, links with a rel attribute containing nofollow are skipped,
+// and no navigation links are returned for a page whose <meta name="robots"> content
+// includes nofollow. When a maximum URL length is set, links longer than it are skipped.
+//
+// When asset extraction is enabled, the URLs of the assets the document uses are returned
+// as well, from the src of <img>, <script> and <source> tags and the href of
+// <link rel="stylesheet"> tags. Assets are found on the same host as baseURL or on the
+// whitelisted domains. Assets on other domains are only included when external asset
+// downloading is enabled.
+func (c *Crawler) FindLinks(baseURL *url.URL, reader io.Reader) LinkSet {
+	return c.findLinks(context.Background(), baseURL, reader)
+}
+
+// findLinks is FindLinks with the records it logs describing the visit in ctx.
+func (c *Crawler) findLinks(ctx context.Context, baseURL *url.URL, reader io.Reader) LinkSet {
+	var (
+		tokenizer   = html.NewTokenizer(reader)
+		foundLinks  = make(map[string]struct{})
+		foundAssets = make(map[string]struct{})
+		resolveURL  = baseURL
+		baseFound   bool
+		noFollow    bool
+	)
+
+	resolve := func(rawUrl string) (*url.URL, bool) {
+		rawUrl = strings.TrimSpace(rawUrl)
+		if rawUrl == "" || strings.HasPrefix(rawUrl, "mailto:") || strings.HasPrefix(rawUrl, "#") {
+			return nil, false
+		}
+
+		parsedUrl, err := url.Parse(rawUrl)
+		if err != nil {
+			c.logger.Debug("invalid link", logAttrs(ctx, rawUrl, "error", err)...)
+			return nil, false
+		}
+
+		parsedUrl.Fragment = ""
+		return resolveURL.ResolveReference(parsedUrl), true
+	}
+
+	addLink := func(rawUrl string) {
+		full, ok := resolve(rawUrl)
+		if !ok {
+			return
+		}
+
+		// Remove the url query params, removes duplicated urls
+		// Example: localhost?lang=en and localhost?lang=sw are the same
+		full.RawQuery = ""
+
+		if !c.allowedLink(baseURL, full) {
+			return
+		}
+
+		link := canonicalize(full)
+
+		if c.maxURLLength > 0 && len(link) > c.maxURLLength {
+			c.logger.Debug("skipping long url", logAttrs(ctx, link, "length", len(link))...)
+			return
+		}
+
+		foundLinks[link] = struct{}{}
+	}
+
+	addAsset := func(rawUrl string) {
+		if !c.extractAssets {
+			return
+		}
+
+		full, ok := resolve(rawUrl)
+		if !ok || !c.allowedAsset(baseURL, full) {
+			return
+		}
+
+		foundAssets[full.String()] = struct{}{}
+	}
 
 	for {
 		switch tt := tokenizer.Next(); tt {
 		case html.ErrorToken:
-			links := make([]string, 0, len(foundLinks))
+			result := LinkSet{
+				Navigation: make([]string, 0, len(foundLinks)),
+				Assets:     make([]string, 0, len(foundAssets)),
+			}
+
+			for asset := range foundAssets {
+				result.Assets = append(result.Assets, asset)
+			}
 
-			delete(foundLinks, baseURL.String())
+			if noFollow {
+				return result
+			}
+
+			delete(foundLinks, canonicalize(baseURL))
 
 			for link := range foundLinks {
-				links = append(links, link)
+				result.Navigation = append(result.Navigation, link)
 			}
-			return links
+			return result
 
-		case html.StartTagToken:
+		case html.StartTagToken, html.SelfClosingTagToken:
 			token := tokenizer.Token()
-			if token.DataAtom != atom.A {
-				continue
-			}
 
-			for _, attr := range token.Attr {
-				if attr.Key != "href" {
+			switch token.DataAtom {
+			case atom.Base:
+				// Only the first <base> tag with an href applies to the document
+				href, ok := attrValue(token, "href")
+				if !ok || baseFound {
+					continue
+				}
+
+				parsedBase, err := url.Parse(strings.TrimSpace(href))
+				if err != nil {
+					c.logger.Debug("invalid base url", logAttrs(ctx, href, "error", err)...)
 					continue
 				}
 
-				rawUrl := strings.TrimSpace(attr.Val)
-				if rawUrl == "" || strings.HasPrefix(rawUrl, "mailto:") || strings.HasPrefix(rawUrl, "#") {
+				resolveURL = baseURL.ResolveReference(parsedBase)
+				baseFound = true
+
+			case atom.A, atom.Area:
+				if rel, _ := attrValue(token, "rel"); c.respectNoFollow && hasToken(rel, "nofollow") {
 					continue
 				}
 
-				parsedUrl, err := url.Parse(rawUrl)
-				if err != nil {
-					log.Printf("invalid URL %q: %v", rawUrl, err)
+				if href, ok := attrValue(token, "href"); ok {
+					addLink(href)
+				}
+
+			case atom.Meta:
+				if name, _ := attrValue(token, "name"); c.respectNoFollow && strings.EqualFold(strings.TrimSpace(name), "robots") {
+					content, _ := attrValue(token, "content")
+					noFollow = noFollow || hasToken(strings.ReplaceAll(content, ",", " "), "nofollow")
 					continue
 				}
 
-				// Remove the url query params, removes duplicated urls
-				// Example: localhost?lang=en and localhost?lang=sw are the same
-				parsedUrl.RawQuery = ""
+				if !c.followMetaRefresh {
+					continue
+				}
 
-				full := baseURL.ResolveReference(parsedUrl)
+				httpEquiv, _ := attrValue(token, "http-equiv")
+				if !strings.EqualFold(strings.TrimSpace(httpEquiv), "refresh") {
+					continue
+				}
 
-				if full.Host != baseURL.Host {
+				content, _ := attrValue(token, "content")
+				if target, ok := metaRefreshURL(content); ok {
+					addLink(target)
+				}
+
+			case atom.Form:
+				if !c.extractFormActions {
 					continue
 				}
 
-				if !strings.HasPrefix(full.Path, baseURL.Path) {
+				method, _ := attrValue(token, "method")
+				switch strings.ToLower(strings.TrimSpace(method)) {
+				case "", "get":
+				case "post":
+					if !c.extractPostForms {
+						continue
+					}
+				default:
 					continue
 				}
 
-				fullStr := strings.TrimRight(full.String(), "/")
-				foundLinks[fullStr] = struct{}{}
+				if action, ok := attrValue(token, "action"); ok {
+					addLink(action)
+				}
+
+			case atom.Img, atom.Script, atom.Source:
+				if src, ok := attrValue(token, "src"); ok {
+					addAsset(src)
+				}
+
+			case atom.Link:
+				rel, _ := attrValue(token, "rel")
+				href, _ := attrValue(token, "href")
+
+				if hasToken(rel, "stylesheet") {
+					addAsset(href)
+				}
+
+				if c.followPagination && (hasToken(rel, "next") || hasToken(rel, "prev")) {
+					addLink(href)
+				}
 			}
 		default:
 			continue
@@ -164,136 +580,859 @@ func (c *Crawler) FindLinks(baseURL *url.URL, reader io.Reader) []string {
 	}
 }
 
-// Fetch retrieves a page from the given URL, either from the disk cache or by downloading it.
+// readPage reads the page stored under key, or the page its alias points to when it
+// duplicates another page.
+func (c *Crawler) readPage(key string) ([]byte, error) {
+	reader, err := c.storage.Read(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		if target, aliasErr := c.readAlias(key); aliasErr == nil {
+			reader, err = c.storage.Read(target)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func(reader io.ReadCloser) {
+		_ = reader.Close()
+	}(reader)
+
+	return io.ReadAll(reader)
+}
+
+// Fetch retrieves a page from the given URL, either from the storage cache or by downloading it.
 //
 // The function first checks if the page has been previously downloaded and cached.
-// If the cached file exists, it reads from the disk. Otherwise, it downloads the page
-// and saves it to the cache directory.
+// If the cached page exists, it reads it from storage, revalidating it with a conditional
+// request when its ETag or Last-Modified validators are known. Otherwise, it downloads
+// the page and saves it to storage. Concurrent fetches of the same page download it only once.
 //
-// After retrieving the content, it parses the HTML to extract all links.
+// After retrieving the content, it parses the HTML to extract all links. When canonical
+// URLs are used, the URL declared by the page's <link rel="canonical"> tag is marked as
+// visited so the same content is not crawled again under that URL.
 func (c *Crawler) Fetch(ctx context.Context, rawURL string) (link []string, err error) {
+	_, result, err := c.fetch(ctx, rawURL)
+	return result.Navigation, err
+}
+
+// fetch retrieves and parses a page like Fetch and also describes the fetched page.
+func (c *Crawler) fetch(ctx context.Context, rawURL string) (PageResult, LinkSet, error) {
+	var page PageResult
+
 	uri, err := url.Parse(rawURL)
 	if err != nil {
-		return nil, fmt.Errorf("parse url: %w", err)
+		return page, LinkSet{}, fmt.Errorf("parse url: %w", err)
+	}
+
+	if c.robots.Respect {
+		allowed, err := c.robotsAllowed(ctx, uri)
+		if err != nil {
+			return page, LinkSet{}, fmt.Errorf("check robots.txt: %w", err)
+		}
+
+		if !allowed {
+			return page, LinkSet{}, ErrDisallowedByRobots
+		}
+	}
+
+	key := c.fileNamer.Name(rawURL)
+
+	buffer, retries, err := c.loadPage(ctx, uri, key)
+	if err != nil {
+		return page, LinkSet{}, err
+	}
+
+	page = PageResult{
+		URL:           rawURL,
+		StatusCode:    http.StatusOK,
+		ContentLength: int64(buffer.Len()),
+		FetchedAt:     time.Now(),
+		Retries:       retries,
+		ContentHash:   contentHash(buffer.Bytes()),
+		LocalFile:     c.localFile(key),
+	}
+
+	metadata, err := ExtractMetadata(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		return page, LinkSet{}, fmt.Errorf("extract metadata: %w", err)
+	}
+
+	page.Metadata = metadata
+
+	if c.saveHeaders {
+		headers, err := c.readHeaders(key)
+		switch {
+		case err == nil:
+			page.Header = headers.Header
+		case !errors.Is(err, fs.ErrNotExist):
+			return page, LinkSet{}, fmt.Errorf("load headers: %w", err)
+		}
+	}
+
+	if c.extractText {
+		text, err := ExtractText(bytes.NewReader(buffer.Bytes()))
+		if err != nil {
+			return page, LinkSet{}, fmt.Errorf("extract text: %w", err)
+		}
+
+		page.TextContent = text
 	}
 
-	filename := alphanumericRegex.ReplaceAllString(rawURL, "_")
-	filename = filepath.Join(c.destinationDir, filename)
+	if c.useCanonical && metadata.Canonical != "" {
+		if canonical, ok := c.resolveCanonical(ctx, uri, metadata.Canonical); ok && canonical != rawURL {
+			c.markVisited(canonical)
+		}
+	}
+
+	bufferCopy := bytes.NewBuffer(buffer.Bytes())
+
+	return page, c.findLinks(ctx, uri, bufferCopy), nil
+}
+
+// loadPage returns the page stored under key, revalidating it when its validators are
+// known, or downloads it when it is not stored. It holds the lock of key so that
+// concurrent fetches of the same page download it only once.
+func (c *Crawler) loadPage(ctx context.Context, uri *url.URL, key string) (*bytes.Buffer, int, error) {
+	unlock := c.lockKey(key)
+	defer unlock()
 
-	contents, err := os.ReadFile(filename)
+	contents, err := c.readPage(key)
 
-	buffer := &bytes.Buffer{}
+	var (
+		buffer  = &bytes.Buffer{}
+		retries int
+	)
 
 	switch {
 	case err == nil:
 		buffer = bytes.NewBuffer(contents)
-	case os.IsNotExist(err):
-		buffer, err = c.DownloadAndSave(ctx, uri.String(), filename)
+
+		// Revalidate cached pages with a conditional request when validators are known
+		entry, ok := c.cacheEntry(uri.String())
+		if !ok {
+			break
+		}
+
+		downloaded, n, err := c.downloadWithRetry(ctx, uri.String(), key, entry)
+		retries = n
+
+		switch {
+		case err == nil:
+			buffer = downloaded
+		case !errors.Is(err, errNotModified):
+			return nil, retries, fmt.Errorf("download and save: %w", err)
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		buffer, retries, err = c.downloadWithRetry(ctx, uri.String(), key, CacheEntry{})
 		if err != nil {
-			return nil, fmt.Errorf("download and save: %w", err)
+			return nil, retries, fmt.Errorf("download and save: %w", err)
 		}
 	case !errors.Is(err, io.EOF):
-		return nil, fmt.Errorf("read file: %w", err)
+		return nil, retries, fmt.Errorf("read file: %w", err)
 	}
 
-	bufferCopy := bytes.NewBuffer(buffer.Bytes())
+	return buffer, retries, nil
+}
+
+// keyLock is the mutex of a storage key, shared by the callers holding or waiting for it.
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lockKey locks the mutex of the storage key and returns the function that unlocks it.
+// The mutex is removed once no caller holds or waits for it.
+func (c *Crawler) lockKey(key string) func() {
+	c.urlLocksMu.Lock()
+	lock, ok := c.urlLocks[key]
+	if !ok {
+		lock = &keyLock{}
+		c.urlLocks[key] = lock
+	}
+	lock.refs++
+	c.urlLocksMu.Unlock()
+
+	lock.mu.Lock()
 
-	links := c.FindLinks(uri, bufferCopy)
-	return links, nil
+	return func() {
+		lock.mu.Unlock()
+
+		c.urlLocksMu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(c.urlLocks, key)
+		}
+		c.urlLocksMu.Unlock()
+	}
 }
 
-// shouldVisit checks if a URL should be visited and marks it as visited atomically
-func (c *Crawler) shouldVisit(rawURL string) bool {
+// resolveCanonical resolves the canonical URL declared by a page against the page's URL
+// and normalizes it like the links from FindLinks.
+func (c *Crawler) resolveCanonical(ctx context.Context, baseURL *url.URL, canonical string) (string, bool) {
+	parsedURL, err := url.Parse(canonical)
+	if err != nil {
+		c.logger.Debug("invalid canonical url", logAttrs(ctx, canonical, "error", err)...)
+		return "", false
+	}
+
+	parsedURL.RawQuery = ""
+	return canonicalize(baseURL.ResolveReference(parsedURL)), true
+}
+
+// matchesFilters reports whether rawURL matches at least one include pattern, when any
+// are configured, and none of the exclude patterns.
+func (c *Crawler) matchesFilters(rawURL string) bool {
+	if len(c.includePatterns) > 0 && !slices.ContainsFunc(c.includePatterns, func(re *regexp.Regexp) bool {
+		return re.MatchString(rawURL)
+	}) {
+		return false
+	}
+
+	return !slices.ContainsFunc(c.excludePatterns, func(re *regexp.Regexp) bool {
+		return re.MatchString(rawURL)
+	})
+}
+
+// shouldVisit checks if a URL should be visited and marks it as visited atomically.
+// URLs are compared in their canonical form, see CanonicalizeURL. URLs with a scheme that
+// is not allowed are never visited. Starting URLs are not subject to the include and
+// exclude patterns.
+//
+// Once the maximum number of pages has been visited, no further URLs are accepted and
+// the crawl is recorded as having reached its page limit. URLs rejected for any other
+// reason are removed from the frontier, so that they are not kept in checkpoints.
+func (c *Crawler) shouldVisit(rawURL string, seed bool) bool {
+	uri, err := url.Parse(rawURL)
+	if err != nil || !c.allowedScheme(uri) || (!seed && !c.matchesFilters(rawURL)) {
+		c.mu.Lock()
+		delete(c.frontier, rawURL)
+		c.mu.Unlock()
+
+		return false
+	}
+
+	key := visitKey(rawURL)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if _, visited := c.visitedPages[rawURL]; visited {
+	// A visit of the same URL that is still in progress adds it back to the frontier
+	// when it does not complete
+	if _, visited := c.visitedPages[key]; visited {
+		delete(c.frontier, rawURL)
+		return false
+	}
+
+	if c.maxPages > 0 && c.pageCount >= c.maxPages {
+		c.pageLimitReached = true
 		return false
 	}
 
-	c.visitedPages[rawURL] = struct{}{}
+	c.visitedPages[key] = struct{}{}
+	c.pageCount++
 	return true
 }
 
+// markVisited records rawURL as visited so that it is not crawled.
+func (c *Crawler) markVisited(rawURL string) {
+	key := visitKey(rawURL)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.visitedPages[key] = struct{}{}
+}
+
+// addFrontier records rawURL as discovered but not yet fetched.
+func (c *Crawler) addFrontier(rawURL string, depth int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, done := c.completed[rawURL]; done {
+		return
+	}
+
+	if depth > c.frontier[rawURL] {
+		c.frontier[rawURL] = depth
+	}
+}
+
+// markCompleted records rawURL as fetched and adds its links to the frontier. The page
+// is remembered as the source of each link it is the first to link to.
+func (c *Crawler) markCompleted(rawURL string, links []string, depth int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.frontier, rawURL)
+	c.completed[rawURL] = struct{}{}
+
+	for _, link := range links {
+		if _, ok := c.sources[link]; !ok {
+			c.sources[link] = rawURL
+		}
+	}
+
+	if depth <= 0 {
+		return
+	}
+
+	for _, link := range links {
+		if _, done := c.completed[link]; done {
+			continue
+		}
+
+		if depth > c.frontier[link] {
+			c.frontier[link] = depth
+		}
+	}
+}
+
+// recordPage adds a fetched page to the crawl results.
+func (c *Crawler) recordPage(page PageResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.results = append(c.results, page)
+}
+
+// recordError adds a failed page to the crawl results.
+func (c *Crawler) recordError(crawlErr CrawlError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.crawlErrors = append(c.crawlErrors, crawlErr)
+	c.errorCount.Add(1)
+}
+
+// callback runs fn on a new goroutine and waits for it to return unless ctx is done first,
+// so a slow callback cannot block cancellation. fn is not called once ctx is done, but a
+// callback abandoned on cancellation keeps running in the background.
+func (c *Crawler) callback(ctx context.Context, fn func()) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		fn()
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// recordBrokenLink adds a link whose target responded with an error status to the crawl results.
+func (c *Crawler) recordBrokenLink(rawURL string, statusCode int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.brokenLinks = append(c.brokenLinks, BrokenLink{
+		SourceURL:  c.sources[rawURL],
+		TargetURL:  rawURL,
+		StatusCode: statusCode,
+		Error:      err.Error(),
+	})
+}
+
+// BrokenLinks returns the links found so far whose target responded with a 4xx or 5xx
+// status. It is safe to call while a crawl is running.
+func (c *Crawler) BrokenLinks() []BrokenLink {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.brokenLinks)
+}
+
+// VisitedCount returns the number of URLs visited so far, including pages that could not
+// be fetched. It is safe to call while a crawl is running.
+func (c *Crawler) VisitedCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return len(c.visitedPages)
+}
+
+// ErrorCount returns the number of pages that could not be fetched so far. It is safe to
+// call while a crawl is running.
+func (c *Crawler) ErrorCount() int {
+	return int(c.errorCount.Load())
+}
+
+// BytesDownloaded returns the number of bytes downloaded over the network so far. It is
+// safe to call while a crawl is running.
+func (c *Crawler) BytesDownloaded() int64 {
+	return c.bytesDownloaded.Load()
+}
+
 // Crawl recursively crawls web pages starting from the given URL to the specified depth.
 //
 // The function fetches the page at rawURL, extracts all links, and recursively
 // crawls each link with depth-1. The crawling stops when the depth reaches 0 or when
 // all reachable pages have been visited.
 func (c *Crawler) Crawl(ctx context.Context, rawURL string, depth int, wg *sync.WaitGroup) {
-	if depth <= 0 {
+	c.crawl(ctx, rawURL, depth, true, wg)
+}
+
+// crawl crawls rawURL like Crawl. seed reports whether rawURL is a starting URL, which is
+// not subject to the include and exclude patterns.
+func (c *Crawler) crawl(ctx context.Context, rawURL string, depth int, seed bool, wg *sync.WaitGroup) {
+	links, ok := c.visit(ctx, rawURL, depth, seed)
+	if !ok {
 		return
 	}
 
-	if !c.shouldVisit(rawURL) {
-		return
+	for _, link := range links {
+		wg.Go(func() {
+			c.crawl(ctx, link, depth-1, false, wg)
+		})
+	}
+}
+
+// visit fetches and records the page at rawURL when it should be visited, and returns
+// the links to crawl next. seed reports whether rawURL is a starting URL. No more than
+// maxConcurrent pages are fetched at the same time.
+func (c *Crawler) visit(ctx context.Context, rawURL string, depth int, seed bool) ([]string, bool) {
+	if depth <= 0 {
+		return nil, false
+	}
+
+	if !c.shouldVisit(rawURL, seed) {
+		return nil, false
+	}
+
+	// keepInFrontier keeps the page in the frontier so that a resumed crawl can fetch it,
+	// even when a rejected visit of the same URL removed it in the meantime
+	keepInFrontier := func() {
+		c.addFrontier(rawURL, depth)
 	}
 
 	if ctx.Err() != nil {
-		return
+		keepInFrontier()
+		return nil, false
 	}
 
-	links, err := c.Fetch(ctx, rawURL)
-	if err != nil {
-		if errors.Is(err, context.Canceled) {
-			return
+	select {
+	case c.semaphore <- struct{}{}:
+	case <-ctx.Done():
+		keepInFrontier()
+		return nil, false
+	}
+
+	// Waiting with the semaphore held keeps every request from starting while paused
+	if err := c.waitResumed(ctx); err != nil {
+		<-c.semaphore
+		keepInFrontier()
+		return nil, false
+	}
+
+	// The host slots are reserved after the pause so that queued requests are still
+	// spaced out once the crawl resumes
+	if uri, err := url.Parse(rawURL); err == nil {
+		if err := c.waitHost(ctx, uri.Host); err != nil {
+			<-c.semaphore
+			keepInFrontier()
+			return nil, false
 		}
-		log.Printf("failed to fetch url: %s %v\n", rawURL, err)
-		return
 	}
 
-	log.Printf("-- %s, found %d link(s)\n", rawURL, len(links))
+	startedAt := time.Now()
+	page, result, err := c.fetch(withVisit(ctx, c.maxDepth-depth, startedAt), rawURL)
+	<-c.semaphore
+
+	links := result.Navigation
+
+	attrs := []any{"url", rawURL, "depth", c.maxDepth - depth, "duration", time.Since(startedAt)}
+
+	if err != nil {
+		// The page stays in the frontier when the crawl was canceled or timed out
+		if ctx.Err() != nil {
+			keepInFrontier()
+			return nil, false
+		}
+
+		if errors.Is(err, ErrByteLimitReached) {
+			keepInFrontier()
+			c.emitProgress(ProgressSkipped, rawURL, c.maxDepth-depth)
+			c.logger.Warn("skipping url", append(attrs, "error", err)...)
+			return nil, false
+		}
+
+		c.markCompleted(rawURL, nil, 0)
+
+		if errors.Is(err, ErrDisallowedByRobots) {
+			c.emitProgress(ProgressSkipped, rawURL, c.maxDepth-depth)
+			c.logger.Warn("skipping url", append(attrs, "error", err)...)
+			return nil, false
+		}
+
+		c.recordError(CrawlError{URL: rawURL, Depth: c.maxDepth - depth, Err: err})
+		c.emitProgress(ProgressError, rawURL, c.maxDepth-depth)
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusBadRequest {
+			c.recordBrokenLink(rawURL, statusErr.StatusCode, err)
+		}
+
+		c.logger.Error("failed to fetch url", append(attrs, "error", err)...)
+		return nil, false
+	}
 
-	var semaphore = make(chan int, c.maxConcurrent)
+	page.Depth = c.maxDepth - depth
+	c.recordPage(page)
+	c.markCompleted(rawURL, links, depth-1)
+	c.emitProgress(ProgressFetched, rawURL, page.Depth)
 
 	for _, link := range links {
-		semaphore <- 1
-		wg.Go(func() {
-			c.Crawl(ctx, link, depth-1, wg)
-			<-semaphore
-		})
+		c.emitProgress(ProgressLinkFound, link, page.Depth+1)
+		c.logger.Debug("found link", "url", link, "depth", page.Depth+1, "duration", time.Since(startedAt), "source", rawURL)
+	}
+
+	if c.linkGraph != nil {
+		for _, link := range links {
+			c.linkGraph.AddEdge(rawURL, link)
+		}
 	}
+
+	if c.onPageFetched != nil {
+		c.callback(ctx, func() { c.onPageFetched(page) })
+	}
+
+	if c.onLinkFound != nil {
+		for _, link := range links {
+			c.callback(ctx, func() { c.onLinkFound(rawURL, link) })
+		}
+	}
+
+	c.logger.Info("fetched url", append(attrs, "links", len(links))...)
+
+	c.downloadAssets(ctx, result.Assets, page.Depth+1)
+	return links, true
 }
 
 // Start begins crawling from the given URL to the specified depth.
-func (c *Crawler) Start(ctx context.Context, rawURL string, depth int) []string {
-	var wg sync.WaitGroup
-	wg.Go(func() {
-		c.Crawl(ctx, rawURL, depth, &wg)
-	})
+//
+// It is StartAll with a single starting URL.
+func (c *Crawler) Start(ctx context.Context, rawURL string, depth int) (CrawlReport, error) {
+	return c.StartAll(ctx, []string{rawURL}, depth)
+}
+
+// StartAll begins crawling from each of the given URLs to the specified depth. Pages
+// reachable from several starting URLs are visited only once.
+//
+// When sitemap fetching is enabled, the URLs listed in each host's sitemap that are
+// children of a starting URL are crawled as additional starting points.
+//
+// When a checkpoint path is configured, the checkpoint is loaded before crawling, saved
+// periodically while crawling and saved again before Start returns, including when the
+// crawl is interrupted.
+//
+// When a maximum number of pages is configured, the crawl stops accepting new URLs once
+// that many pages have been visited, and pages already being fetched are allowed to finish.
+// The report is then marked as interrupted. Likewise, when a maximum number of bytes is
+// configured, no new downloads are started once that many bytes have been downloaded.
+//
+// When a manifest path is configured, a manifest of the fetched pages is saved there
+// before Start returns, see ManifestEntry.
+//
+// When a crawl timeout is configured, ctx is wrapped with that timeout and the crawl stops
+// once it expires. The report is then marked as interrupted with the reason "timeout".
+//
+// An error is returned when no URLs are given or when any of them is not an absolute URL.
+func (c *Crawler) StartAll(ctx context.Context, urls []string, depth int) (CrawlReport, error) {
+	if len(urls) == 0 {
+		return CrawlReport{}, errors.New("at least one url is required")
+	}
 
-	wg.Wait()
+	startURLs := make([]*url.URL, 0, len(urls))
 
-	links := make([]string, 0, len(c.visitedPages))
+	for _, rawURL := range urls {
+		startURL, err := url.Parse(rawURL)
+		if err != nil {
+			return CrawlReport{}, fmt.Errorf("parse url: %w", err)
+		}
 
-	for link := range c.visitedPages {
-		links = append(links, link)
+		if startURL.Scheme == "" || startURL.Host == "" {
+			return CrawlReport{}, fmt.Errorf("url must include scheme and host: %q", rawURL)
+		}
+
+		startURLs = append(startURLs, startURL)
 	}
 
-	return links
+	c.running.Store(true)
+	defer c.running.Store(false)
 
-}
+	parent := ctx
+	if c.crawlTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.crawlTimeout)
+		defer cancel()
+	}
+
+	c.startedAt = time.Now()
+	c.maxDepth = depth
+
+	stopProgress := c.startProgress()
+	defer stopProgress()
+
+	stopCheckpoints := func() {}
+
+	if c.checkpointPath != "" {
+		if err := c.LoadCheckpoint(c.checkpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			c.logger.Error("failed to load checkpoint", "path", c.checkpointPath, "error", err)
+		}
+
+		checkpointCtx, stop := context.WithCancel(ctx)
+		saved := make(chan struct{})
+
+		go func() {
+			defer close(saved)
+			c.saveCheckpointPeriodically(checkpointCtx)
+		}()
+
+		stopCheckpoints = func() {
+			stop()
+			<-saved
+		}
+	}
+
+	// Only the given URLs are starting URLs; sitemap and checkpoint URLs are filtered
+	seeds := make(map[string]struct{}, len(urls))
+
+	for i, startURL := range startURLs {
+		c.addFrontier(urls[i], depth)
+		seeds[urls[i]] = struct{}{}
+
+		if !c.fetchSitemap {
+			continue
+		}
+
+		for _, seed := range c.sitemapSeeds(ctx, startURL) {
+			c.addFrontier(seed, depth)
+		}
+	}
+
+	// The frontier also holds pending URLs restored from a checkpoint
+	c.mu.RLock()
+	pending := make(map[string]int, len(c.frontier))
+	for link, linkDepth := range c.frontier {
+		pending[link] = linkDepth
+	}
+	c.mu.RUnlock()
+
+	switch c.traversal {
+	case TraversalBFS:
+		c.crawlBFS(ctx, pending, seeds)
+	default:
+		var wg sync.WaitGroup
+		for link, linkDepth := range pending {
+			_, seed := seeds[link]
+			wg.Go(func() {
+				c.crawl(ctx, link, linkDepth, seed, &wg)
+			})
+		}
+
+		wg.Wait()
+	}
 
-// NewCrawler creates a new Crawler instance with the specified configuration.
-func NewCrawler(httpClient HttpClient, destinationDir string) (*Crawler, error) {
-	if destinationDir == "" {
-		destinationDir = DestinationDir
+	if err := c.saveCacheMetadata(); err != nil {
+		c.logger.Error("failed to save cache metadata", "error", err)
 	}
 
-	if err := os.MkdirAll(destinationDir, os.ModePerm); err != nil {
-		return nil, fmt.Errorf("mkdir: %w", err)
+	// The periodic saves must be done before the final one so that none overwrites it
+	stopCheckpoints()
+
+	if c.checkpointPath != "" {
+		if err := c.SaveCheckpoint(c.checkpointPath); err != nil {
+			c.logger.Error("failed to save checkpoint", "path", c.checkpointPath, "error", err)
+		}
 	}
 
-	if httpClient == nil {
-		httpClient = &http.Client{
-			Timeout: 30 * time.Second,
+	if c.manifestPath != "" {
+		if err := c.saveManifest(c.manifestPath); err != nil {
+			c.logger.Error("failed to save manifest", "path", c.manifestPath, "error", err)
 		}
 	}
 
-	return &Crawler{
-		destinationDir: destinationDir,
-		httpClient:     httpClient,
-		visitedPages:   make(map[string]struct{}),
-		maxConcurrent:  runtime.NumCPU(),
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	reason := c.interruptReason(parent, ctx)
+
+	return CrawlReport{
+		VisitedURLs:     slices.Clone(c.results),
+		TotalBytes:      c.BytesDownloaded(),
+		Duration:        time.Since(c.startedAt),
+		Errors:          slices.Clone(c.crawlErrors),
+		BrokenLinks:     slices.Clone(c.brokenLinks),
+		Duplicates:      maps.Clone(c.duplicates),
+		Interrupted:     reason != "",
+		InterruptReason: reason,
 	}, nil
 }
+
+// interruptReason returns why a crawl stopped early, or an empty string when it was not
+// interrupted. parent is the context passed to StartAll and ctx the one the crawl ran with.
+// The caller must hold c.mu.
+func (c *Crawler) interruptReason(parent, ctx context.Context) string {
+	switch {
+	case parent.Err() != nil:
+		return InterruptCanceled
+	case ctx.Err() != nil:
+		return InterruptTimeout
+	case c.pageLimitReached:
+		return InterruptMaxPages
+	case c.byteLimitReached.Load():
+		return InterruptMaxBytes
+	default:
+		return ""
+	}
+}
+
+// Reset clears the state of previous crawls so that the crawler can be reused: the visited
+// pages, the frontier, the results, the errors and the counters. When the crawler is
+// configured to clear the cache on reset, the stored pages and their cache metadata are
+// deleted as well, so the next crawl downloads every page again.
+//
+// Reset must not be called while a crawl is in progress and returns an error if it is.
+// Clearing the cache returns an error when the storage cannot delete every key at once.
+func (c *Crawler) Reset() error {
+	if c.running.Load() {
+		return errors.New("reset crawler: crawl in progress")
+	}
+
+	c.mu.Lock()
+	c.visitedPages = make(map[string]struct{})
+	c.claimedAssets = make(map[string]struct{})
+	c.completed = make(map[string]struct{})
+	c.frontier = make(map[string]int)
+	c.sources = make(map[string]string)
+	c.contentHashes = make(map[string]string)
+	c.duplicates = make(map[string]string)
+	c.results = nil
+	c.crawlErrors = nil
+	c.brokenLinks = nil
+	c.pageCount = 0
+	c.pageLimitReached = false
+	c.mu.Unlock()
+
+	c.bytesDownloaded.Store(0)
+	c.errorCount.Store(0)
+	c.byteLimitReached.Store(false)
+
+	if !c.resetClearCache {
+		return nil
+	}
+
+	storage, ok := c.storage.(clearer)
+	if !ok {
+		return fmt.Errorf("reset crawler: storage %T cannot be cleared", c.storage)
+	}
+
+	if err := storage.Clear(); err != nil {
+		return fmt.Errorf("clear storage: %w", err)
+	}
+
+	c.cacheMu.Lock()
+	c.cacheMetadata = make(map[string]CacheEntry)
+	c.cacheMu.Unlock()
+
+	return nil
+}
+
+// New creates a new Crawler configured by the given options.
+//
+// Without options, pages are saved to a FileStorage in DestinationDir, requests are made with an
+// http.Client using DefaultTimeout, and up to runtime.NumCPU() links are crawled
+// in parallel.
+func New(opts ...Option) (*Crawler, error) {
+	c := &Crawler{
+		destinationDir:     DestinationDir,
+		urlLocks:           make(map[string]*keyLock),
+		visitedPages:       make(map[string]struct{}),
+		claimedAssets:      make(map[string]struct{}),
+		completed:          make(map[string]struct{}),
+		frontier:           make(map[string]int),
+		sources:            make(map[string]string),
+		contentHashes:      make(map[string]string),
+		duplicates:         make(map[string]string),
+		maxConcurrent:      runtime.NumCPU(),
+		traversal:          TraversalDFS,
+		fileNamer:          AlphanumericNamer{},
+		logger:             slog.Default(),
+		timeout:            DefaultTimeout,
+		checkpointInterval: DefaultCheckpointInterval,
+		retryAttempts:      1,
+		progressBufferSize: DefaultProgressBufferSize,
+		robotsCache:        make(map[string]*robotsEntry),
+		cacheMetadata:      make(map[string]CacheEntry),
+		rateLimiter:        NewDomainRateLimiter(0, 0),
+		lastRequestTime:    make(map[string]time.Time),
+		allowedSchemes:     map[string]struct{}{"http": {}, "https": {}},
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, fmt.Errorf("apply option: %w", err)
+		}
+	}
+
+	c.semaphore = make(chan struct{}, c.maxConcurrent)
+
+	if c.storage == nil && c.memoryOnly {
+		c.storage = NewMemoryStorage()
+	}
+
+	if c.storage == nil {
+		storage, err := NewFileStorage(c.destinationDir)
+		if err != nil {
+			return nil, err
+		}
+
+		c.storage = storage
+	}
+
+	if err := c.loadCacheMetadata(); err != nil {
+		return nil, err
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{
+			Timeout:   c.timeout,
+			Transport: c.newTransport(),
+		}
+	} else {
+		c.warnIgnoredTransportOptions()
+	}
+
+	if c.cookieJar != nil {
+		c.httpClient = withCookieJar(c.httpClient, c.cookieJar)
+	}
+
+	if len(c.headers) > 0 {
+		c.httpClient = withHeaders(c.httpClient, c.headers)
+	}
+
+	return c, nil
+}
+
+// NewCrawler creates a new Crawler that uses httpClient to make requests and saves pages
+// to destinationDir. A nil httpClient or an empty destinationDir fall back to the defaults
+// used by New. Additional options are applied after the two arguments.
+func NewCrawler(httpClient HttpClient, destinationDir string, opts ...Option) (*Crawler, error) {
+	var base []Option
+
+	if httpClient != nil {
+		base = append(base, WithHTTPClient(httpClient))
+	}
+
+	if destinationDir != "" {
+		base = append(base, WithDestinationDir(destinationDir))
+	}
+
+	return New(append(base, opts...)...)
+}
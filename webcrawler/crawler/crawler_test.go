@@ -5,11 +5,20 @@ import (
 	"fmt"
 	"kitchen/pkg/assert"
 	"kitchen/pkg/testutil"
+	"log/slog"
+	"maps"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 const testDestinationDir = "testdata"
@@ -50,13 +59,11 @@ func TestCrawler_DownloadAndSave(t *testing.T) {
 		</html>`
 		})
 
-		filename := filepath.Join(testDestinationDir, "localhost")
-
-		buffer, err := crawler.DownloadAndSave(ctx, link, filename)
+		buffer, err := crawler.DownloadAndSave(ctx, link, "localhost")
 		assert.Nil(t, err)
 		assert.NotNil(t, buffer)
 
-		_, err = os.Stat(filename)
+		_, err = os.Stat(filepath.Join(testDestinationDir, "localhost"))
 		assert.Nil(t, err)
 	})
 
@@ -102,16 +109,14 @@ func TestCrawler_FindLinks(t *testing.T) {
 	crawler, err := NewCrawler(httpClient, testDestinationDir)
 	assert.Nil(t, err)
 
-	filename := filepath.Join(testDestinationDir, "localhost")
-
-	buffer, err := crawler.DownloadAndSave(ctx, link, filename)
+	buffer, err := crawler.DownloadAndSave(ctx, link, "localhost")
 	assert.Nil(t, err)
 	assert.NotNil(t, buffer)
 
 	uri, err := url.Parse(link)
 	assert.Nil(t, err)
 
-	links := crawler.FindLinks(uri, buffer)
+	links := crawler.FindLinks(uri, buffer).Navigation
 	assert.NotNil(t, links)
 	assert.Equal[int](t, 3, len(links))
 }
@@ -139,6 +144,1266 @@ func TestCrawler_Crawl(t *testing.T) {
 	crawler, err := NewCrawler(httpClient, testDestinationDir)
 	assert.Nil(t, err)
 
-	links := crawler.Start(ctx, link, 10)
-	assert.Equal(t, len(links), 4)
+	report, err := crawler.Start(ctx, link, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, report.URLs(), []string{link})
+	assert.Equal(t, len(report.Errors), 3)
+
+	for _, crawlErr := range report.Errors {
+		assert.ErrorIs(t, crawlErr, ErrPageNotFound)
+	}
+}
+
+func TestCrawler_Start(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		home       = `<a href="/about">About</a><a href="/broken">Broken</a>`
+		about      = `<p>About us</p>`
+	)
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, home
+	})
+
+	httpClient.Request(link+"/about", func() (code int, body string) {
+		return http.StatusOK, about
+	})
+
+	httpClient.Request(link+"/broken", func() (code int, body string) {
+		return http.StatusInternalServerError, ""
+	})
+
+	t.Run("reports pages, bytes and errors", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 3)
+		assert.Nil(t, err)
+		assert.False(t, report.Interrupted)
+		assert.True(t, report.Duration > 0)
+		assert.Equal(t, len(report.VisitedURLs), 2)
+		assert.Equal(t, report.TotalBytes, int64(len(home)+len(about)))
+
+		for _, page := range report.VisitedURLs {
+			assert.Equal(t, page.StatusCode, http.StatusOK)
+			assert.False(t, page.FetchedAt.IsZero())
+
+			switch page.URL {
+			case link:
+				assert.Equal(t, page.Depth, 0)
+				assert.Equal(t, page.ContentLength, int64(len(home)))
+			case link + "/about":
+				assert.Equal(t, page.Depth, 1)
+				assert.Equal(t, page.ContentLength, int64(len(about)))
+			default:
+				t.Errorf("unexpected page: %s", page.URL)
+			}
+		}
+
+		assert.Equal(t, len(report.Errors), 1)
+		assert.Equal(t, report.Errors[0].URL, link+"/broken")
+		assert.Equal(t, report.Errors[0].Depth, 1)
+	})
+
+	t.Run("cached pages are not counted as downloaded", func(t *testing.T) {
+		dir := t.TempDir()
+
+		crawler, err := NewCrawler(httpClient, dir)
+		assert.Nil(t, err)
+
+		_, err = crawler.Start(ctx, link, 3)
+		assert.Nil(t, err)
+
+		crawler, err = NewCrawler(httpClient, dir)
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 2)
+		assert.Equal(t, report.TotalBytes, int64(0))
+	})
+
+	t.Run("interrupted crawl", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 3)
+		assert.Nil(t, err)
+		assert.True(t, report.Interrupted)
+		assert.Equal(t, len(report.VisitedURLs), 0)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		_, err = crawler.Start(ctx, "localhost.com", 3)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCrawler_Callbacks(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+	)
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, `<a href="/about">About</a><a href="/contact">Contact</a>`
+	})
+
+	httpClient.Request(link+"/about", func() (code int, body string) {
+		return http.StatusOK, `<a href="/about/team">Team</a>`
+	})
+
+	httpClient.Request(link+"/contact", func() (code int, body string) {
+		return http.StatusOK, ""
+	})
+
+	httpClient.Request(link+"/about/team", func() (code int, body string) {
+		return http.StatusOK, ""
+	})
+
+	t.Run("callbacks match the report", func(t *testing.T) {
+		var (
+			mu    sync.Mutex
+			pages []string
+			edges []string
+		)
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(),
+			WithOnPageFetched(func(page PageResult) {
+				mu.Lock()
+				defer mu.Unlock()
+				pages = append(pages, page.URL)
+			}),
+			WithOnLinkFound(func(from, to string) {
+				mu.Lock()
+				defer mu.Unlock()
+				edges = append(edges, from+" -> "+to)
+			}),
+		)
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 3)
+		assert.Nil(t, err)
+
+		urls := report.URLs()
+		slices.Sort(urls)
+		slices.Sort(pages)
+		slices.Sort(edges)
+
+		assert.Equal(t, pages, urls)
+		assert.Equal(t, edges, []string{
+			link + " -> " + link + "/about",
+			link + " -> " + link + "/contact",
+			link + "/about -> " + link + "/about/team",
+		})
+	})
+
+	t.Run("blocking callback does not block cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		block := make(chan struct{})
+		defer close(block)
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(),
+			WithOnPageFetched(func(page PageResult) {
+				cancel()
+				<-block
+			}),
+		)
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 3)
+		assert.Nil(t, err)
+		assert.True(t, report.Interrupted)
+	})
+
+	t.Run("callbacks are not called after cancellation", func(t *testing.T) {
+		var linksFound atomic.Int32
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(),
+			WithOnPageFetched(func(page PageResult) {
+				cancel()
+			}),
+			WithOnLinkFound(func(from, to string) {
+				linksFound.Add(1)
+			}),
+		)
+		assert.Nil(t, err)
+
+		_, err = crawler.Start(ctx, link, 3)
+		assert.Nil(t, err)
+
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, linksFound.Load(), int32(0))
+	})
+}
+
+func TestCrawler_Patterns(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+	)
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, `
+			<a href="/docs/intro">Intro</a>
+			<a href="/docs/guide">Guide</a>
+			<a href="/blog/post">Post</a>
+			<a href="/cdn/docs/logo">Logo</a>
+			<a href="/docs/cdn/asset">Asset</a>`
+	})
+
+	for _, path := range []string{"/docs/intro", "/docs/guide", "/blog/post", "/cdn/docs/logo", "/docs/cdn/asset"} {
+		httpClient.Request(link+path, func() (code int, body string) {
+			return http.StatusOK, ""
+		})
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{
+			name: "include",
+			opts: []Option{WithIncludePattern(`/docs/.*`)},
+			want: []string{link, link + "/cdn/docs/logo", link + "/docs/cdn/asset", link + "/docs/guide", link + "/docs/intro"},
+		},
+		{
+			name: "include and exclude",
+			opts: []Option{WithIncludePattern(`/docs/.*`), WithExcludePattern(`/cdn/`)},
+			want: []string{link, link + "/docs/guide", link + "/docs/intro"},
+		},
+		{
+			name: "multiple includes",
+			opts: []Option{WithIncludePattern(`/docs/intro$`), WithIncludePattern(`/blog/`)},
+			want: []string{link, link + "/blog/post", link + "/docs/intro"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crawler, err := NewCrawler(httpClient, t.TempDir(), tt.opts...)
+			assert.Nil(t, err)
+
+			report, err := crawler.Start(ctx, link, 3)
+			assert.Nil(t, err)
+
+			urls := report.URLs()
+			slices.Sort(urls)
+			assert.Equal(t, urls, tt.want)
+		})
+	}
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := NewCrawler(httpClient, t.TempDir(), WithIncludePattern(`(`))
+		assert.NotNil(t, err)
+
+		_, err = NewCrawler(httpClient, t.TempDir(), WithExcludePattern(`[`))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCrawler_DomainLists(t *testing.T) {
+	var (
+		link       = "http://www.example.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		evilHits   int
+	)
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, `
+			<a href="/about">About</a>
+			<a href="http://docs.example.com/guide">Guide</a>
+			<a href="http://evil.com/phish">Phish</a>`
+	})
+
+	httpClient.Request(link+"/about", func() (code int, body string) {
+		return http.StatusOK, ""
+	})
+
+	httpClient.Request("http://docs.example.com/guide", func() (code int, body string) {
+		return http.StatusOK, `<a href="http://evil.com/phish">Phish</a>`
+	})
+
+	httpClient.Request("http://evil.com/phish", func() (code int, body string) {
+		evilHits++
+		return http.StatusOK, ""
+	})
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{
+			name: "same host by default",
+			want: []string{link, link + "/about"},
+		},
+		{
+			name: "whitelist",
+			opts: []Option{WithDomainWhitelist("example.com", "docs.example.com")},
+			want: []string{"http://docs.example.com/guide", link, link + "/about"},
+		},
+		{
+			name: "blacklist wins over whitelist",
+			opts: []Option{
+				WithDomainWhitelist("example.com", "docs.example.com", "evil.com"),
+				WithDomainBlacklist("EVIL.com", "docs.example.com"),
+			},
+			want: []string{link, link + "/about"},
+		},
+		{
+			name: "blacklist own host",
+			opts: []Option{WithDomainBlacklist("example.com")},
+			want: []string{link},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crawler, err := NewCrawler(httpClient, t.TempDir(), tt.opts...)
+			assert.Nil(t, err)
+
+			report, err := crawler.Start(ctx, link, 3)
+			assert.Nil(t, err)
+
+			urls := report.URLs()
+			slices.Sort(urls)
+			assert.Equal(t, urls, tt.want)
+		})
+	}
+
+	assert.Equal(t, evilHits, 0)
+}
+
+func TestCrawler_BrokenLinks(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+	)
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, `<a href="/about">About</a><a href="/status">Status</a>`
+	})
+
+	httpClient.Request(link+"/about", func() (code int, body string) {
+		return http.StatusOK, `<a href="/about/missing">Missing</a>`
+	})
+
+	httpClient.Request(link+"/status", func() (code int, body string) {
+		return http.StatusBadGateway, ""
+	})
+
+	crawler, err := NewCrawler(httpClient, t.TempDir())
+	assert.Nil(t, err)
+
+	report, err := crawler.Start(ctx, link, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, len(report.BrokenLinks), 2)
+	assert.Equal(t, crawler.BrokenLinks(), report.BrokenLinks)
+
+	for _, broken := range report.BrokenLinks {
+		switch broken.TargetURL {
+		case link + "/about/missing":
+			assert.Equal(t, broken.SourceURL, link+"/about")
+			assert.Equal(t, broken.StatusCode, http.StatusNotFound)
+			assert.True(t, broken.IsClientError())
+			assert.False(t, broken.IsServerError())
+		case link + "/status":
+			assert.Equal(t, broken.SourceURL, link)
+			assert.Equal(t, broken.StatusCode, http.StatusBadGateway)
+			assert.False(t, broken.IsClientError())
+			assert.True(t, broken.IsServerError())
+		default:
+			t.Errorf("unexpected broken link: %s", broken.TargetURL)
+		}
+		assert.NotEqual(t, broken.Error, "")
+	}
+}
+
+func TestCrawler_FindLinksBaseHref(t *testing.T) {
+	crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir())
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name    string
+		pageURL string
+		html    string
+		want    []string
+	}{
+		{
+			name:    "absolute path base",
+			pageURL: "http://localhost.com",
+			html: `
+				<head><base href="/subdir/"><base href="/ignored/"></head>
+				<a href="page">Page</a>
+				<a href="nested/page">Nested</a>
+				<a href="/root">Root</a>`,
+			want: []string{
+				"http://localhost.com/root",
+				"http://localhost.com/subdir/nested/page",
+				"http://localhost.com/subdir/page",
+			},
+		},
+		{
+			name:    "relative base",
+			pageURL: "http://localhost.com/dir/",
+			html:    `<base href="docs/" /><a href="intro">Intro</a>`,
+			want:    []string{"http://localhost.com/dir/docs/intro"},
+		},
+		{
+			name:    "base without href",
+			pageURL: "http://localhost.com/dir/",
+			html:    `<base target="_blank"><base href="sub/"><a href="page">Page</a>`,
+			want:    []string{"http://localhost.com/dir/sub/page"},
+		},
+		{
+			name:    "no base",
+			pageURL: "http://localhost.com/dir/",
+			html:    `<a href="page">Page</a>`,
+			want:    []string{"http://localhost.com/dir/page"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uri, err := url.Parse(tt.pageURL)
+			assert.Nil(t, err)
+
+			links := crawler.FindLinks(uri, strings.NewReader(tt.html)).Navigation
+			slices.Sort(links)
+			assert.Equal(t, links, tt.want)
+		})
+	}
+}
+
+func TestCrawler_FindLinksMetaRefresh(t *testing.T) {
+	uri, err := url.Parse("http://localhost.com")
+	assert.Nil(t, err)
+
+	page := `
+		<head><meta http-equiv="Refresh" content="0; URL='/new-page'"></head>
+		<a href="/about">About</a>`
+
+	t.Run("follows meta refresh", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir(), WithFollowMetaRefresh(true))
+		assert.Nil(t, err)
+
+		links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+		slices.Sort(links)
+		assert.Equal(t, links, []string{"http://localhost.com/about", "http://localhost.com/new-page"})
+	})
+
+	t.Run("ignores meta refresh by default", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir())
+		assert.Nil(t, err)
+
+		links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+		assert.Equal(t, links, []string{"http://localhost.com/about"})
+	})
+
+	t.Run("parses content", func(t *testing.T) {
+		tests := []struct {
+			content string
+			want    string
+			ok      bool
+		}{
+			{content: "0; url=/new-page", want: "/new-page", ok: true},
+			{content: "5;URL=http://localhost.com/next", want: "http://localhost.com/next", ok: true},
+			{content: `0; url="/quoted"`, want: "/quoted", ok: true},
+			{content: "0; /bare", want: "/bare", ok: true},
+			{content: "30", ok: false},
+			{content: "0; url=", ok: false},
+		}
+
+		for _, tt := range tests {
+			got, ok := metaRefreshURL(tt.content)
+			assert.Equal(t, ok, tt.ok)
+			assert.Equal(t, got, tt.want)
+		}
+	})
+}
+
+func TestCrawler_FindLinksNoFollow(t *testing.T) {
+	uri, err := url.Parse("http://localhost.com")
+	assert.Nil(t, err)
+
+	page := `
+		<a href="/about">About</a>
+		<a href="/login" rel="nofollow">Login</a>
+		<a href="/ads" rel="sponsored NoFollow">Ads</a>
+		<a href="/contact" rel="noopener">Contact</a>`
+
+	t.Run("skips nofollow links", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir(), WithRespectNoFollow(true))
+		assert.Nil(t, err)
+
+		links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+		slices.Sort(links)
+		assert.Equal(t, links, []string{"http://localhost.com/about", "http://localhost.com/contact"})
+	})
+
+	t.Run("follows nofollow links by default", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir())
+		assert.Nil(t, err)
+
+		links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+		assert.Equal(t, len(links), 4)
+	})
+
+	t.Run("skips all links on nofollow pages", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir(), WithRespectNoFollow(true))
+		assert.Nil(t, err)
+
+		robotsPage := `<head><meta name="robots" content="noindex, nofollow"></head>` + page
+
+		links := crawler.FindLinks(uri, strings.NewReader(robotsPage)).Navigation
+		assert.Equal(t, len(links), 0)
+	})
+}
+
+func TestCrawler_UseCanonical(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		sorted     = `<head><link rel="canonical" href="/page"></head><a href="/page">Page</a>`
+	)
+
+	httpClient.Request(link+"/page?sort=asc", func() (code int, body string) {
+		return http.StatusOK, sorted
+	})
+
+	httpClient.Request(link+"/page", func() (code int, body string) {
+		return http.StatusOK, `<p>Page</p>`
+	})
+
+	t.Run("marks canonical url as visited", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithUseCanonical(true))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link+"/page?sort=asc", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, report.URLs(), []string{link + "/page?sort=asc"})
+
+		_, visited := crawler.visitedPages[link+"/page?sort=asc"]
+		assert.True(t, visited)
+
+		_, visited = crawler.visitedPages[link+"/page"]
+		assert.True(t, visited)
+	})
+
+	t.Run("crawls canonical url by default", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link+"/page?sort=asc", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 2)
+	})
+}
+
+func TestCrawler_MaxPages(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		home       strings.Builder
+	)
+
+	for i := range 20 {
+		page := fmt.Sprintf("%s/page-%d", link, i)
+		fmt.Fprintf(&home, `<a href="%s">Page %d</a>`, page, i)
+
+		httpClient.Request(page, func() (code int, body string) {
+			return http.StatusOK, `<p>Page</p>`
+		})
+	}
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, home.String()
+	})
+
+	t.Run("stops at the page limit", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithMaxPages(5))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 5)
+		assert.True(t, report.Interrupted)
+		assert.Equal(t, report.InterruptReason, InterruptMaxPages)
+	})
+
+	t.Run("is not interrupted below the page limit", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithMaxPages(50))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 21)
+		assert.False(t, report.Interrupted)
+		assert.Equal(t, report.InterruptReason, "")
+	})
+}
+
+func TestCrawler_MaxBytes(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		content    = strings.Repeat("a", 1000)
+		home       strings.Builder
+	)
+
+	for i := range 20 {
+		page := fmt.Sprintf("%s/page-%d", link, i)
+		fmt.Fprintf(&home, `<a href="%s">Page %d</a>`, page, i)
+
+		httpClient.Request(page, func() (code int, body string) {
+			return http.StatusOK, content
+		})
+	}
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, home.String()
+	})
+
+	t.Run("stops downloading at the byte limit", func(t *testing.T) {
+		const maxBytes = 5000
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithMaxBytes(maxBytes), WithMaxConcurrent(1))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+		assert.True(t, report.Interrupted)
+		assert.Equal(t, report.InterruptReason, InterruptMaxBytes)
+		assert.True(t, report.TotalBytes >= maxBytes)
+		assert.True(t, report.TotalBytes <= maxBytes+int64(len(content)))
+		assert.Equal(t, len(report.Errors), 0)
+	})
+
+	t.Run("returns an error once the limit is reached", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithMaxBytes(1))
+		assert.Nil(t, err)
+
+		_, err = crawler.DownloadAndSave(ctx, link+"/page-0", "page-0")
+		assert.Nil(t, err)
+
+		_, err = crawler.DownloadAndSave(ctx, link+"/page-1", "page-1")
+		assert.ErrorIs(t, err, ErrByteLimitReached)
+	})
+}
+
+func TestCrawler_CrawlTimeout(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		home       strings.Builder
+	)
+
+	for i := range 50 {
+		page := fmt.Sprintf("%s/page-%d", link, i)
+		fmt.Fprintf(&home, `<a href="%s">Page %d</a>`, page, i)
+
+		httpClient.Request(page, func() (code int, body string) {
+			time.Sleep(20 * time.Millisecond)
+			return http.StatusOK, `<p>Page</p>`
+		})
+	}
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, home.String()
+	})
+
+	t.Run("stops when the timeout expires", func(t *testing.T) {
+		goroutines := runtime.NumGoroutine()
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithCrawlTimeout(100*time.Millisecond), WithMaxConcurrent(2))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+		assert.True(t, len(report.VisitedURLs) > 0)
+		assert.True(t, len(report.VisitedURLs) < 51)
+		assert.True(t, report.Interrupted)
+		assert.Equal(t, report.InterruptReason, InterruptTimeout)
+
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		assert.True(t, runtime.NumGoroutine() <= goroutines)
+	})
+
+	t.Run("reports cancellation by the caller", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithCrawlTimeout(time.Minute))
+		assert.Nil(t, err)
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+		assert.True(t, report.Interrupted)
+		assert.Equal(t, report.InterruptReason, InterruptCanceled)
+	})
+}
+
+func TestCrawler_StartAll(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		pages      = map[string]string{
+			link:                 `<a href="/about">About</a><a href="/docs">Docs</a>`,
+			link + "/about":      `<p>About</p>`,
+			link + "/docs":       `<a href="/docs/intro">Intro</a>`,
+			link + "/docs/intro": `<p>Intro</p>`,
+			link + "/blog":       `<a href="/blog/post">Post</a>`,
+			link + "/blog/post":  `<p>Post</p>`,
+		}
+	)
+
+	for page, body := range pages {
+		httpClient.Request(page, func() (int, string) {
+			return http.StatusOK, body
+		})
+	}
+
+	t.Run("crawls pages reachable from any seed", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		report, err := crawler.StartAll(ctx, []string{link, link + "/docs", link + "/blog"}, 3)
+		assert.Nil(t, err)
+
+		urls := report.URLs()
+		slices.Sort(urls)
+
+		want := slices.Sorted(maps.Keys(pages))
+		assert.Equal(t, urls, want)
+	})
+
+	t.Run("rejects invalid seeds", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		_, err = crawler.StartAll(ctx, nil, 3)
+		assert.NotNil(t, err)
+
+		_, err = crawler.StartAll(ctx, []string{link, "/relative"}, 3)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCrawler_Reset(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		version    = "v1"
+	)
+
+	httpClient.Request(link, func() (int, string) {
+		return http.StatusOK, `<a href="/about">About</a>`
+	})
+
+	httpClient.Request(link+"/about", func() (int, string) {
+		return http.StatusOK, version
+	})
+
+	readAbout := func(t *testing.T, crawler *Crawler) string {
+		contents, err := crawler.readPage(alphanumericRegex.ReplaceAllString(link+"/about", "_"))
+		assert.Nil(t, err)
+		return string(contents)
+	}
+
+	t.Run("revisits pages with fresh content", func(t *testing.T) {
+		version = "v1"
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithResetClearCache(true))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 2)
+		assert.Equal(t, readAbout(t, crawler), "v1")
+
+		version = "v2"
+		assert.Nil(t, crawler.Reset())
+		assert.Equal(t, crawler.bytesDownloaded.Load(), int64(0))
+
+		report, err = crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 2)
+		assert.Equal(t, report.TotalBytes, int64(len(`<a href="/about">About</a>`)+len("v2")))
+		assert.Equal(t, readAbout(t, crawler), "v2")
+	})
+
+	t.Run("keeps the cache by default", func(t *testing.T) {
+		version = "v1"
+
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		_, err = crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+
+		version = "v2"
+		assert.Nil(t, crawler.Reset())
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 2)
+		assert.Equal(t, report.TotalBytes, int64(0))
+		assert.Equal(t, readAbout(t, crawler), "v1")
+	})
+
+	t.Run("fails while crawling", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		crawler.running.Store(true)
+		assert.NotNil(t, crawler.Reset())
+	})
+}
+
+// recordHandler is a slog.Handler that captures every record it handles.
+type recordHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordHandler) Handle(_ context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.records = append(h.records, record)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordHandler) WithGroup(string) slog.Handler { return h }
+
+// find returns the first record with message msg.
+func (h *recordHandler) find(msg string) (slog.Record, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, record := range h.records {
+		if record.Message == msg {
+			return record, true
+		}
+	}
+
+	return slog.Record{}, false
+}
+
+func TestCrawler_Logging(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		handler    = &recordHandler{}
+	)
+
+	httpClient.Request(link, func() (int, string) {
+		return http.StatusOK, `<a href="/about">About</a><a href="/broken">Broken</a>
+			<a href="/a-link-that-is-far-too-long">Long</a><img src="/missing.png">`
+	})
+
+	httpClient.Request(link+"/about", func() (int, string) {
+		return http.StatusOK, `<p>About</p>`
+	})
+
+	httpClient.Request(link+"/sitemap.xml", func() (int, string) {
+		return http.StatusInternalServerError, ""
+	})
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(),
+		WithLogger(slog.New(handler)),
+		WithMaxURLLength(40),
+		WithExtractAssets(true),
+		WithFetchSitemap(true),
+	)
+	assert.Nil(t, err)
+
+	_, err = crawler.Start(ctx, link, 2)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		msg   string
+		level slog.Level
+		keys  []string
+	}{
+		{msg: "fetched url", level: slog.LevelInfo, keys: []string{"url", "depth", "duration", "links"}},
+		{msg: "failed to fetch url", level: slog.LevelError, keys: []string{"url", "depth", "duration", "error"}},
+		{msg: "found link", level: slog.LevelDebug, keys: []string{"url", "depth", "duration", "source"}},
+		{msg: "skipping long url", level: slog.LevelDebug, keys: []string{"url", "depth", "duration", "length"}},
+		{msg: "failed to download asset", level: slog.LevelWarn, keys: []string{"url", "depth", "duration", "error"}},
+		{msg: "failed to fetch sitemap", level: slog.LevelWarn, keys: []string{"url", "depth", "duration", "error"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			record, ok := handler.find(tt.msg)
+			assert.True(t, ok)
+			assert.Equal(t, record.Level, tt.level)
+
+			var keys []string
+			record.Attrs(func(attr slog.Attr) bool {
+				keys = append(keys, attr.Key)
+				return true
+			})
+
+			assert.Equal(t, keys, tt.keys)
+		})
+	}
+
+	t.Run("every record describes its url, depth and duration", func(t *testing.T) {
+		handler.mu.Lock()
+		defer handler.mu.Unlock()
+
+		for _, record := range handler.records {
+			keys := make(map[string]bool)
+			record.Attrs(func(attr slog.Attr) bool {
+				keys[attr.Key] = true
+				return true
+			})
+
+			for _, key := range []string{"url", "depth", "duration"} {
+				if !keys[key] {
+					t.Errorf("record %q has no %s attribute", record.Message, key)
+				}
+			}
+		}
+	})
+}
+
+func TestCrawler_FindLinksFormActions(t *testing.T) {
+	uri, err := url.Parse("http://localhost.com/shop")
+	assert.Nil(t, err)
+
+	page := `
+		<form action="/shop/search"><input name="q"></form>
+		<form method="GET" action="/shop/filter?size=large"><input name="color"></form>
+		<form method="post" action="/shop/cart"><button>Add</button></form>
+		<form method="dialog"><button>Close</button></form>
+		<a href="/shop/about">About</a>`
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{
+			name: "ignores forms by default",
+			want: []string{"http://localhost.com/shop/about"},
+		},
+		{
+			name: "extracts get form actions",
+			opts: []Option{WithExtractFormActions(true)},
+			want: []string{
+				"http://localhost.com/shop/about",
+				"http://localhost.com/shop/filter",
+				"http://localhost.com/shop/search",
+			},
+		},
+		{
+			name: "extracts post form actions",
+			opts: []Option{WithExtractFormActions(true), WithExtractPostForms(true)},
+			want: []string{
+				"http://localhost.com/shop/about",
+				"http://localhost.com/shop/cart",
+				"http://localhost.com/shop/filter",
+				"http://localhost.com/shop/search",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir(), tt.opts...)
+			assert.Nil(t, err)
+
+			links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+			slices.Sort(links)
+			assert.Equal(t, links, tt.want)
+		})
+	}
+}
+
+func TestCrawler_FindLinksImageMap(t *testing.T) {
+	uri, err := url.Parse("http://localhost.com")
+	assert.Nil(t, err)
+
+	page := `
+		<img src="/map.png" usemap="#regions">
+		<map name="regions">
+			<area shape="rect" coords="0,0,10,10" href="/region1">
+			<area shape="circle" coords="20,20,5" href="/region2?zoom=2" />
+			<area shape="default" nohref>
+		</map>`
+
+	crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir())
+	assert.Nil(t, err)
+
+	links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+	slices.Sort(links)
+	assert.Equal(t, links, []string{"http://localhost.com/region1", "http://localhost.com/region2"})
+}
+
+func TestCrawler_FindLinksPagination(t *testing.T) {
+	uri, err := url.Parse("http://localhost.com")
+	assert.Nil(t, err)
+
+	page := `
+		<head>
+			<link rel="prev" href="/page/1">
+			<link rel="next" href="/page/3">
+			<link rel="stylesheet" href="/site.css">
+		</head>
+		<a href="/about">About</a>`
+
+	t.Run("follows pagination links", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir(), WithFollowPagination(true))
+		assert.Nil(t, err)
+
+		links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+		slices.Sort(links)
+		assert.Equal(t, links, []string{
+			"http://localhost.com/about",
+			"http://localhost.com/page/1",
+			"http://localhost.com/page/3",
+		})
+	})
+
+	t.Run("ignores pagination links by default", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir())
+		assert.Nil(t, err)
+
+		links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+		assert.Equal(t, links, []string{"http://localhost.com/about"})
+	})
+}
+
+func TestCrawler_AllowedSchemes(t *testing.T) {
+	var (
+		link       = "https://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		page       = `
+			<a href="ftp://localhost.com/files">Files</a>
+			<a href="http://localhost.com/plain">Plain</a>
+			<a href="https://localhost.com/secure">Secure</a>`
+	)
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, page
+	})
+
+	for _, path := range []string{"/plain", "/secure"} {
+		httpClient.Request(link+path, func() (code int, body string) {
+			return http.StatusOK, `<p>Page</p>`
+		})
+	}
+
+	uri, err := url.Parse(link)
+	assert.Nil(t, err)
+
+	t.Run("follows http and https links by default", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+		slices.Sort(links)
+		assert.Equal(t, links, []string{"http://localhost.com/plain", "https://localhost.com/secure"})
+	})
+
+	t.Run("follows only allowed schemes", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithAllowedSchemes("https"))
+		assert.Nil(t, err)
+
+		links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+		assert.Equal(t, links, []string{"https://localhost.com/secure"})
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+
+		urls := report.URLs()
+		slices.Sort(urls)
+		assert.Equal(t, urls, []string{link, link + "/secure"})
+	})
+
+	t.Run("extracts only assets with an allowed scheme", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithAllowedSchemes("https"), WithExtractAssets(true))
+		assert.Nil(t, err)
+
+		result := crawler.FindLinks(uri, strings.NewReader(`
+			<img src="http://localhost.com/plain.png">
+			<img src="https://localhost.com/secure.png">`))
+		assert.Equal(t, result.Assets, []string{"https://localhost.com/secure.png"})
+	})
+
+	t.Run("does not visit a starting url with a scheme that is not allowed", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithAllowedSchemes("https"))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, "http://localhost.com/plain", 1)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 0)
+	})
+}
+
+func TestCrawler_FindLinksMaxURLLength(t *testing.T) {
+	uri, err := url.Parse("http://localhost.com")
+	assert.Nil(t, err)
+
+	long := "/" + strings.Repeat("a", 100)
+	page := fmt.Sprintf(`
+		<a href="/about">About</a>
+		<a href="/contact?utm_source=%s">Contact</a>
+		<a href="%s">Long</a>`, strings.Repeat("b", 100), long)
+
+	t.Run("returns every link without a limit", func(t *testing.T) {
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir())
+		assert.Nil(t, err)
+
+		links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+		slices.Sort(links)
+		assert.Equal(t, links, []string{
+			"http://localhost.com" + long,
+			"http://localhost.com/about",
+			"http://localhost.com/contact",
+		})
+	})
+
+	t.Run("skips links longer than the limit", func(t *testing.T) {
+		handler := &recordHandler{}
+
+		crawler, err := NewCrawler(testutil.NewTestHttpClient(), t.TempDir(),
+			WithMaxURLLength(50), WithLogger(slog.New(handler)))
+		assert.Nil(t, err)
+
+		links := crawler.FindLinks(uri, strings.NewReader(page)).Navigation
+		slices.Sort(links)
+		assert.Equal(t, links, []string{"http://localhost.com/about", "http://localhost.com/contact"})
+
+		record, ok := handler.find("skipping long url")
+		assert.True(t, ok)
+		assert.Equal(t, record.Level, slog.LevelDebug)
+	})
+}
+
+func TestCrawler_FetchConcurrent(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`<a href="/about">About</a>`))
+	}))
+	t.Cleanup(server.Close)
+
+	crawler, err := New(WithDestinationDir(t.TempDir()))
+	assert.Nil(t, err)
+
+	var (
+		wg  sync.WaitGroup
+		ctx = context.Background()
+	)
+
+	for range 50 {
+		wg.Go(func() {
+			links, err := crawler.Fetch(ctx, server.URL)
+			assert.Nil(t, err)
+			assert.Equal(t, links, []string{server.URL + "/about"})
+		})
+	}
+
+	wg.Wait()
+	assert.Equal(t, requests.Load(), int32(1))
+	assert.Equal(t, len(crawler.urlLocks), 0)
+}
+
+func TestCrawler_Counters(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		home       strings.Builder
+	)
+
+	for i := range 20 {
+		page := fmt.Sprintf("%s/page-%d", link, i)
+		fmt.Fprintf(&home, `<a href="%s">Page %d</a>`, page, i)
+
+		if i%4 == 0 {
+			continue
+		}
+
+		httpClient.Request(page, func() (int, string) {
+			return http.StatusOK, `<p>Page</p>`
+		})
+	}
+
+	httpClient.Request(link, func() (int, string) {
+		return http.StatusOK, home.String()
+	})
+
+	crawler, err := NewCrawler(httpClient, t.TempDir())
+	assert.Nil(t, err)
+
+	var (
+		done    = make(chan struct{})
+		stopped = make(chan struct{})
+	)
+
+	go func() {
+		defer close(stopped)
+
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			if crawler.VisitedCount() < 0 || crawler.ErrorCount() < 0 || crawler.BytesDownloaded() < 0 {
+				t.Error("counter is negative")
+				return
+			}
+		}
+	}()
+
+	report, err := crawler.Start(ctx, link, 2)
+	close(done)
+	<-stopped
+
+	assert.Nil(t, err)
+	assert.Equal(t, crawler.VisitedCount(), 21)
+	assert.Equal(t, crawler.ErrorCount(), len(report.Errors))
+	assert.Equal(t, crawler.ErrorCount(), 5)
+	assert.Equal(t, crawler.BytesDownloaded(), report.TotalBytes)
 }
@@ -0,0 +1,87 @@
+package crawler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"strings"
+)
+
+// aliasSuffix is added to the storage key of a duplicate page to name the alias entry that
+// holds the storage key of the page with the same content.
+const aliasSuffix = ".alias"
+
+// contentHash returns the hex encoded SHA-256 hash of contents.
+func contentHash(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// recordContent remembers the SHA-256 hash of a downloaded page and returns the first URL
+// downloaded with the same content when it differs from uri. Duplicates are recorded with
+// that URL.
+func (c *Crawler) recordContent(uri string, contents []byte) (string, bool) {
+	hash := contentHash(contents)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	first, ok := c.contentHashes[hash]
+	if !ok {
+		c.contentHashes[hash] = uri
+		return "", false
+	}
+
+	if first == uri {
+		return "", false
+	}
+
+	c.duplicates[uri] = first
+	return first, true
+}
+
+// saveAlias records that the page stored under key has the same content as the page at
+// the first URL, so that it is read from the storage key of that page.
+func (c *Crawler) saveAlias(key string, first string) error {
+	if err := c.storage.Write(key+aliasSuffix, strings.NewReader(c.fileNamer.Name(first))); err != nil {
+		return fmt.Errorf("save alias: %w", err)
+	}
+
+	return nil
+}
+
+// readAlias returns the storage key that the alias entry of key points to. An error
+// matching fs.ErrNotExist is returned when key has no alias entry.
+func (c *Crawler) readAlias(key string) (string, error) {
+	reader, err := c.storage.Read(key + aliasSuffix)
+	if err != nil {
+		return "", err
+	}
+
+	defer func(reader io.ReadCloser) {
+		_ = reader.Close()
+	}(reader)
+
+	target, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("read alias: %w", err)
+	}
+
+	if len(target) == 0 || string(target) == key {
+		return "", fmt.Errorf("read alias %s: %w", key, fs.ErrNotExist)
+	}
+
+	return string(target), nil
+}
+
+// Duplicates returns the URLs whose content duplicates a page downloaded earlier, mapped
+// to the URL of that page. It is safe to call while a crawl is running.
+func (c *Crawler) Duplicates() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return maps.Clone(c.duplicates)
+}
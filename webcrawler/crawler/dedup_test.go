@@ -0,0 +1,119 @@
+package crawler
+
+import (
+	"context"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCrawler_DeduplicateContent(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		mirrored   = `<p>Same content</p>`
+	)
+
+	httpClient.Request(link, func() (int, string) {
+		return http.StatusOK, `<a href="/a">A</a><a href="/b">B</a>`
+	})
+
+	for _, page := range []string{link + "/a", link + "/b"} {
+		httpClient.Request(page, func() (int, string) {
+			return http.StatusOK, mirrored
+		})
+	}
+
+	countPages := func(t *testing.T, dir string) int {
+		entries, err := os.ReadDir(dir)
+		assert.Nil(t, err)
+
+		var count int
+		for _, entry := range entries {
+			name := entry.Name()
+			if name != cacheMetadataFile && name != storageIndexFile && !strings.HasSuffix(name, aliasSuffix) {
+				count++
+			}
+		}
+
+		return count
+	}
+
+	t.Run("stores duplicate content once", func(t *testing.T) {
+		dir := t.TempDir()
+
+		crawler, err := NewCrawler(httpClient, dir, WithDeduplicateContent(true), WithMaxConcurrent(1))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 3)
+		assert.Equal(t, countPages(t, dir), 2)
+		assert.Equal(t, len(report.Duplicates), 1)
+
+		for duplicate, original := range report.Duplicates {
+			assert.NotEqual(t, duplicate, original)
+			assert.True(t, duplicate == link+"/a" || duplicate == link+"/b")
+			assert.True(t, original == link+"/a" || original == link+"/b")
+		}
+	})
+
+	t.Run("reads duplicates from the stored page", func(t *testing.T) {
+		var (
+			dir        = t.TempDir()
+			httpClient = testutil.NewTestHttpClient()
+			requests   atomic.Int32
+		)
+
+		for _, page := range []string{link + "/a", link + "/b"} {
+			httpClient.Request(page, func() (int, string) {
+				requests.Add(1)
+				return http.StatusOK, mirrored
+			})
+		}
+
+		crawler, err := NewCrawler(httpClient, dir, WithDeduplicateContent(true))
+		assert.Nil(t, err)
+
+		_, err = crawler.Fetch(ctx, link+"/a")
+		assert.Nil(t, err)
+
+		_, err = crawler.Fetch(ctx, link+"/b")
+		assert.Nil(t, err)
+		assert.Equal(t, crawler.Duplicates(), map[string]string{link + "/b": link + "/a"})
+		assert.Equal(t, requests.Load(), int32(2))
+
+		_, err = crawler.Fetch(ctx, link+"/b")
+		assert.Nil(t, err)
+		assert.Equal(t, requests.Load(), int32(2))
+
+		// A resumed crawl reads the duplicate from storage as well
+		crawler, err = NewCrawler(httpClient, dir, WithDeduplicateContent(true))
+		assert.Nil(t, err)
+
+		contents, err := crawler.readPage(crawler.fileNamer.Name(link + "/b"))
+		assert.Nil(t, err)
+		assert.Equal(t, string(contents), mirrored)
+
+		_, err = crawler.Fetch(ctx, link+"/b")
+		assert.Nil(t, err)
+		assert.Equal(t, requests.Load(), int32(2))
+	})
+
+	t.Run("stores every page by default", func(t *testing.T) {
+		dir := t.TempDir()
+
+		crawler, err := NewCrawler(httpClient, dir)
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, countPages(t, dir), 3)
+		assert.Equal(t, len(report.Duplicates), 0)
+	})
+}
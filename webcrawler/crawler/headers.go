@@ -0,0 +1,56 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// headersSuffix is appended to the storage key of a page to form the key of the sidecar
+// file that holds the page's response headers.
+const headersSuffix = ".headers.json"
+
+// pageHeaders is the response a page was downloaded with, as saved in its headers sidecar.
+type pageHeaders struct {
+	Header     http.Header `json:"header"`
+	StatusCode int         `json:"status_code"`
+	FetchedAt  time.Time   `json:"fetched_at"`
+}
+
+// writeHeaders saves the headers and status code of resp to the sidecar of the page
+// stored under key.
+func (c *Crawler) writeHeaders(key string, resp *http.Response) error {
+	data, err := json.Marshal(pageHeaders{
+		Header:     resp.Header,
+		StatusCode: resp.StatusCode,
+		FetchedAt:  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal headers: %w", err)
+	}
+
+	if err := c.storage.Write(key+headersSuffix, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("write headers: %w", err)
+	}
+
+	return nil
+}
+
+// readHeaders loads the sidecar of the page stored under key. The returned error matches
+// fs.ErrNotExist when the page has no sidecar.
+func (c *Crawler) readHeaders(key string) (pageHeaders, error) {
+	var headers pageHeaders
+
+	data, err := c.readPage(key + headersSuffix)
+	if err != nil {
+		return headers, fmt.Errorf("read headers: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &headers); err != nil {
+		return headers, fmt.Errorf("unmarshal headers: %w", err)
+	}
+
+	return headers, nil
+}
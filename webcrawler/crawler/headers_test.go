@@ -0,0 +1,61 @@
+package crawler
+
+import (
+	"context"
+	"io/fs"
+	"kitchen/pkg/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWithSaveHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Kitchen", "pancakes")
+		_, _ = w.Write([]byte("<p>Hello</p>"))
+	}))
+	t.Cleanup(server.Close)
+
+	var (
+		ctx = context.Background()
+		key = alphanumericRegex.ReplaceAllString(server.URL, "_")
+	)
+
+	t.Run("does not save headers by default", func(t *testing.T) {
+		crawler, err := New(WithDestinationDir(t.TempDir()))
+		assert.Nil(t, err)
+
+		_, err = crawler.DownloadAndSave(ctx, server.URL, key)
+		assert.Nil(t, err)
+
+		_, err = crawler.readHeaders(key)
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("saves and reloads headers", func(t *testing.T) {
+		dir := t.TempDir()
+
+		crawler, err := New(WithDestinationDir(dir), WithSaveHeaders(true))
+		assert.Nil(t, err)
+
+		_, err = crawler.DownloadAndSave(ctx, server.URL, key)
+		assert.Nil(t, err)
+
+		headers, err := crawler.readHeaders(key)
+		assert.Nil(t, err)
+		assert.Equal(t, headers.StatusCode, http.StatusOK)
+		assert.Equal(t, headers.Header.Get("Content-Type"), "text/html; charset=utf-8")
+		assert.False(t, headers.FetchedAt.IsZero())
+
+		// The page and its headers are loaded from the cache once the server is gone
+		server.Close()
+
+		crawler, err = New(WithDestinationDir(dir), WithSaveHeaders(true))
+		assert.Nil(t, err)
+
+		page, _, err := crawler.fetch(ctx, server.URL)
+		assert.Nil(t, err)
+		assert.Equal(t, page.Header.Get("X-Kitchen"), "pancakes")
+	})
+}
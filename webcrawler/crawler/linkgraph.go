@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// Edge is a link from one page to another.
+type Edge struct {
+	From string
+	To   string
+}
+
+// LinkGraph records the links between crawled pages. The zero value is an empty graph
+// ready to use, and it is safe for concurrent use.
+type LinkGraph struct {
+	mu    sync.RWMutex
+	edges map[string][]string
+}
+
+// AddEdge records a link from one page to another. Duplicate edges are ignored.
+func (g *LinkGraph) AddEdge(from, to string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.edges == nil {
+		g.edges = make(map[string][]string)
+	}
+
+	if slices.Contains(g.edges[from], to) {
+		return
+	}
+
+	g.edges[from] = append(g.edges[from], to)
+}
+
+// Edges returns every edge in the graph, sorted by source and then target URL.
+func (g *LinkGraph) Edges() []Edge {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var edges []Edge
+	for from, targets := range g.edges {
+		for _, to := range targets {
+			edges = append(edges, Edge{From: from, To: to})
+		}
+	}
+
+	slices.SortFunc(edges, func(a, b Edge) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To))
+	})
+
+	return edges
+}
+
+// ExportDOT writes the graph to w in the Graphviz DOT format.
+func (g *LinkGraph) ExportDOT(w io.Writer) error {
+	writer := bufio.NewWriter(w)
+
+	_, _ = fmt.Fprintln(writer, "digraph links {")
+	for _, edge := range g.Edges() {
+		_, _ = fmt.Fprintf(writer, "\t%s -> %s;\n", strconv.Quote(edge.From), strconv.Quote(edge.To))
+	}
+	_, _ = fmt.Fprintln(writer, "}")
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("write dot: %w", err)
+	}
+
+	return nil
+}
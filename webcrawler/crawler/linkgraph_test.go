@@ -0,0 +1,59 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"testing"
+)
+
+func TestLinkGraph(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		graph      = &LinkGraph{}
+	)
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, `<a href="/about">About</a><a href="/contact">Contact</a>`
+	})
+
+	httpClient.Request(link+"/about", func() (code int, body string) {
+		return http.StatusOK, `<a href="/">Home</a>`
+	})
+
+	httpClient.Request(link+"/contact", func() (code int, body string) {
+		return http.StatusOK, ""
+	})
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(), WithLinkGraph(graph))
+	assert.Nil(t, err)
+
+	_, err = crawler.Start(ctx, link, 3)
+	assert.Nil(t, err)
+
+	assert.Equal(t, graph.Edges(), []Edge{
+		{From: link, To: link + "/about"},
+		{From: link, To: link + "/contact"},
+	})
+
+	var dot bytes.Buffer
+	assert.Nil(t, graph.ExportDOT(&dot))
+	assert.Equal(t, dot.String(), `digraph links {
+	"http://localhost.com" -> "http://localhost.com/about";
+	"http://localhost.com" -> "http://localhost.com/contact";
+}
+`)
+
+	t.Run("ignores duplicate edges", func(t *testing.T) {
+		var graph LinkGraph
+		graph.AddEdge("a", "b")
+		graph.AddEdge("a", "b")
+		graph.AddEdge("b", "a")
+
+		assert.Equal(t, graph.Edges(), []Edge{{From: "a", To: "b"}, {From: "b", To: "a"}})
+	})
+}
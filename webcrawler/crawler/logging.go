@@ -0,0 +1,34 @@
+package crawler
+
+import (
+	"context"
+	"time"
+)
+
+// visitKeyType is the context key of the visit that a request is made for.
+type visitKeyType struct{}
+
+// visitInfo describes the visit that a request is made for.
+type visitInfo struct {
+	depth     int
+	startedAt time.Time
+}
+
+// withVisit returns a copy of ctx that carries the depth of the visit and when it started,
+// so that the records logged while making its requests include them.
+func withVisit(ctx context.Context, depth int, startedAt time.Time) context.Context {
+	return context.WithValue(ctx, visitKeyType{}, visitInfo{depth: depth, startedAt: startedAt})
+}
+
+// logAttrs returns the attributes of a record about rawURL: its url, the depth and
+// duration of the visit in ctx, and then args. The depth and duration are left out when
+// ctx carries no visit, such as when FindLinks is called directly.
+func logAttrs(ctx context.Context, rawURL string, args ...any) []any {
+	attrs := []any{"url", rawURL}
+
+	if visit, ok := ctx.Value(visitKeyType{}).(visitInfo); ok {
+		attrs = append(attrs, "depth", visit.depth, "duration", time.Since(visit.startedAt))
+	}
+
+	return append(attrs, args...)
+}
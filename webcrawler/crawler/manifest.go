@@ -0,0 +1,68 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ManifestEntry describes a fetched page in the manifest saved when a manifest path is
+// configured.
+type ManifestEntry struct {
+	URL string `json:"url"`
+	// LocalFile is the path of the file the page is stored in. It is empty when the page is
+	// not stored in a local file.
+	LocalFile  string    `json:"local_file"`
+	StatusCode int       `json:"status_code"`
+	FetchedAt  time.Time `json:"fetched_at"`
+	// ContentHash is the hex encoded SHA-256 hash of the page content.
+	ContentHash string `json:"content_hash"`
+	SizeBytes   int64  `json:"size_bytes"`
+}
+
+// localFile returns the path of the file key is stored in, or an empty string when the
+// storage does not keep keys in local files or key is not stored.
+func (c *Crawler) localFile(key string) string {
+	storage, ok := c.storage.(locator)
+	if !ok {
+		return ""
+	}
+
+	if exists, err := c.storage.Exists(key); err != nil || !exists {
+		return ""
+	}
+
+	return storage.path(key)
+}
+
+// saveManifest writes a JSON array of a ManifestEntry for each fetched page to path. The
+// file is written with writeFileAtomic.
+func (c *Crawler) saveManifest(path string) error {
+	c.mu.RLock()
+
+	entries := make([]ManifestEntry, 0, len(c.results))
+	for _, page := range c.results {
+		entries = append(entries, ManifestEntry{
+			URL:         page.URL,
+			LocalFile:   page.LocalFile,
+			StatusCode:  page.StatusCode,
+			FetchedAt:   page.FetchedAt,
+			ContentHash: page.ContentHash,
+			SizeBytes:   page.ContentLength,
+		})
+	}
+
+	c.mu.RUnlock()
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+
+	if err := writeFileAtomic(path, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package crawler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWithManifest(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		pages      = map[string]string{
+			link:            `<a href="/about">About</a><a href="/blog">Blog</a>`,
+			link + "/about": `<p>About</p>`,
+			link + "/blog":  `<p>Blog</p>`,
+		}
+	)
+
+	for page, body := range pages {
+		httpClient.Request(page, func() (code int, _ string) {
+			return http.StatusOK, body
+		})
+	}
+
+	readManifest := func(t *testing.T, path string) []ManifestEntry {
+		t.Helper()
+
+		data, err := os.ReadFile(path)
+		assert.Nil(t, err)
+
+		var entries []ManifestEntry
+		assert.Nil(t, json.Unmarshal(data, &entries))
+
+		slices.SortFunc(entries, func(a, b ManifestEntry) int {
+			return strings.Compare(a.URL, b.URL)
+		})
+
+		return entries
+	}
+
+	t.Run("lists the local file of each fetched page", func(t *testing.T) {
+		manifest := filepath.Join(t.TempDir(), "manifest.json")
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithManifest(manifest))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+
+		entries := readManifest(t, manifest)
+		assert.Equal(t, len(entries), len(report.VisitedURLs))
+
+		urls := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			urls = append(urls, entry.URL)
+
+			contents, err := os.ReadFile(entry.LocalFile)
+			assert.Nil(t, err)
+			assert.Equal(t, string(contents), pages[entry.URL])
+
+			sum := sha256.Sum256(contents)
+			assert.Equal(t, entry.ContentHash, hex.EncodeToString(sum[:]))
+			assert.Equal(t, entry.SizeBytes, int64(len(contents)))
+			assert.Equal(t, entry.StatusCode, http.StatusOK)
+			assert.False(t, entry.FetchedAt.IsZero())
+		}
+
+		assert.Equal(t, urls, []string{link, link + "/about", link + "/blog"})
+	})
+
+	t.Run("leaves the local file empty for a memory storage", func(t *testing.T) {
+		manifest := filepath.Join(t.TempDir(), "manifest.json")
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithMemoryOnly(true), WithManifest(manifest))
+		assert.Nil(t, err)
+
+		_, err = crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+
+		entries := readManifest(t, manifest)
+		assert.Equal(t, len(entries), 3)
+
+		for _, entry := range entries {
+			assert.Equal(t, entry.LocalFile, "")
+			assert.NotEqual(t, entry.ContentHash, "")
+		}
+	})
+
+	t.Run("does not save a manifest by default", func(t *testing.T) {
+		dir := t.TempDir()
+
+		crawler, err := NewCrawler(httpClient, dir)
+		assert.Nil(t, err)
+
+		_, err = crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+
+		_, err = os.Stat(filepath.Join(dir, "manifest.json"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
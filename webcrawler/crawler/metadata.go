@@ -0,0 +1,94 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// PageMetadata holds the metadata an HTML page declares about itself.
+type PageMetadata struct {
+	// Title is the text of the <title> tag.
+	Title string
+	// Description is the content of the <meta name="description"> tag.
+	Description string
+	// OGTitle, OGDescription and OGImage are the content of the og:title, og:description
+	// and og:image Open Graph <meta property> tags.
+	OGTitle       string
+	OGDescription string
+	OGImage       string
+	// Canonical is the href of the <link rel="canonical"> tag, as written in the document.
+	Canonical string
+}
+
+// ExtractMetadata reads an HTML document and returns the metadata it declares. When a
+// tag appears more than once, the first occurrence is used.
+func ExtractMetadata(r io.Reader) (PageMetadata, error) {
+	var (
+		tokenizer = html.NewTokenizer(r)
+		metadata  PageMetadata
+		inTitle   bool
+	)
+
+	setOnce := func(field *string, value string) {
+		if *field == "" {
+			*field = strings.TrimSpace(value)
+		}
+	}
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); !errors.Is(err, io.EOF) {
+				return metadata, fmt.Errorf("parse html: %w", err)
+			}
+
+			return metadata, nil
+
+		case html.TextToken:
+			if inTitle {
+				setOnce(&metadata.Title, string(tokenizer.Text()))
+			}
+
+		case html.EndTagToken:
+			if token := tokenizer.Token(); token.DataAtom == atom.Title {
+				inTitle = false
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+
+			switch token.DataAtom {
+			case atom.Title:
+				inTitle = true
+
+			case atom.Meta:
+				content, _ := attrValue(token, "content")
+
+				if name, _ := attrValue(token, "name"); strings.EqualFold(name, "description") {
+					setOnce(&metadata.Description, content)
+				}
+
+				property, _ := attrValue(token, "property")
+				switch strings.ToLower(property) {
+				case "og:title":
+					setOnce(&metadata.OGTitle, content)
+				case "og:description":
+					setOnce(&metadata.OGDescription, content)
+				case "og:image":
+					setOnce(&metadata.OGImage, content)
+				}
+
+			case atom.Link:
+				if rel, _ := attrValue(token, "rel"); hasToken(rel, "canonical") {
+					href, _ := attrValue(token, "href")
+					setOnce(&metadata.Canonical, href)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package crawler
+
+import (
+	"context"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExtractMetadata(t *testing.T) {
+	t.Run("extracts metadata", func(t *testing.T) {
+		page := `
+			<html>
+			<head>
+				<title> Kitchen Recipes </title>
+				<meta name="description" content="Recipes from the kitchen">
+				<meta property="og:title" content="Kitchen">
+				<meta property="og:description" content="Cook something">
+				<meta property="og:image" content="https://localhost.com/cover.png">
+				<link rel="canonical" href="/recipes">
+			</head>
+			<body><title>Ignored</title></body>
+			</html>`
+
+		metadata, err := ExtractMetadata(strings.NewReader(page))
+		assert.Nil(t, err)
+		assert.Equal(t, metadata, PageMetadata{
+			Title:         "Kitchen Recipes",
+			Description:   "Recipes from the kitchen",
+			OGTitle:       "Kitchen",
+			OGDescription: "Cook something",
+			OGImage:       "https://localhost.com/cover.png",
+			Canonical:     "/recipes",
+		})
+	})
+
+	t.Run("returns empty metadata for pages without it", func(t *testing.T) {
+		metadata, err := ExtractMetadata(strings.NewReader(`<p>Hello</p>`))
+		assert.Nil(t, err)
+		assert.Equal(t, metadata, PageMetadata{})
+	})
+
+	t.Run("attaches metadata to fetched pages", func(t *testing.T) {
+		var (
+			link       = "http://localhost.com"
+			httpClient = testutil.NewTestHttpClient()
+		)
+
+		httpClient.Request(link, func() (int, string) {
+			return http.StatusOK, `<title>Home</title><meta name="description" content="Welcome">`
+		})
+
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(context.Background(), link, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 1)
+		assert.Equal(t, report.VisitedURLs[0].Metadata.Title, "Home")
+		assert.Equal(t, report.VisitedURLs[0].Metadata.Description, "Welcome")
+	})
+}
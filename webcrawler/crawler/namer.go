@@ -0,0 +1,104 @@
+package crawler
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// FileNamer names the storage key a fetched page is saved under.
+type FileNamer interface {
+	Name(rawURL string) string
+}
+
+// AlphanumericNamer names pages by replacing every run of non-alphanumeric characters in
+// the URL with an underscore. It is the default FileNamer. Different URLs can map to the
+// same name, such as URLs that only differ in punctuation.
+type AlphanumericNamer struct{}
+
+// Name implements FileNamer.
+func (AlphanumericNamer) Name(rawURL string) string {
+	return alphanumericRegex.ReplaceAllString(rawURL, "_")
+}
+
+// MD5Namer names pages with the hex-encoded MD5 hash of the URL, so that different URLs
+// map to different names.
+type MD5Namer struct{}
+
+// Name implements FileNamer.
+func (MD5Namer) Name(rawURL string) string {
+	sum := md5.Sum([]byte(rawURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// pathSegmentRegex matches the characters that are not kept in a PathNamer path segment.
+var pathSegmentRegex = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// PathNamer names pages after their URL, mirroring the host and path into directories
+// with each page saved as index.html in the directory of its path, such as
+// "example.com/blog/post/index.html" for "https://example.com/blog/post". When the last
+// path segment has a file extension, it is used as the file name instead, so that
+// "https://example.com/blog/index.html" is saved as "example.com/blog/index.html" rather
+// than in a directory named after a file. The query string, when present, is added to the
+// file name.
+//
+// Each path segment is sanitized and empty segments are skipped, so that a name never
+// escapes the storage directory.
+type PathNamer struct{}
+
+// Name implements FileNamer.
+func (PathNamer) Name(rawURL string) string {
+	uri, err := url.Parse(rawURL)
+	if err != nil {
+		return path.Join("_", sanitizeSegment(rawURL), "index.html")
+	}
+
+	segments := []string{sanitizeSegment(uri.Host)}
+
+	for _, segment := range strings.Split(uri.Path, "/") {
+		if segment == "" {
+			continue
+		}
+
+		segments = append(segments, sanitizeSegment(segment))
+	}
+
+	base, ext := "index", ".html"
+	if last := segments[len(segments)-1]; len(segments) > 1 && !strings.HasSuffix(uri.Path, "/") {
+		if stem, found := fileStem(last); found {
+			base, ext = stem, path.Ext(last)
+			segments = segments[:len(segments)-1]
+		}
+	}
+
+	if uri.RawQuery != "" {
+		base += "_" + sanitizeSegment(uri.RawQuery)
+	}
+
+	return path.Join(append(segments, base+ext)...)
+}
+
+// fileStem returns segment without its file extension and reports whether segment has
+// both a name and an extension, such as "index.html".
+func fileStem(segment string) (string, bool) {
+	ext := path.Ext(segment)
+	stem := strings.TrimSuffix(segment, ext)
+	return stem, len(ext) > 1 && stem != "" && stem != "_"
+}
+
+// sanitizeSegment replaces the characters of a path segment that are unsafe in file names
+// with underscores. Segments that would refer to the current or parent directory are
+// replaced as well.
+func sanitizeSegment(segment string) string {
+	segment = pathSegmentRegex.ReplaceAllString(segment, "_")
+
+	switch segment {
+	case "", ".", "..":
+		return "_"
+	}
+
+	return segment
+}
@@ -0,0 +1,103 @@
+package crawler
+
+import (
+	"context"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileNamer(t *testing.T) {
+	var (
+		first  = "http://localhost.com/recipes/pan-cakes"
+		second = "http://localhost.com/recipes/pan_cakes"
+	)
+
+	t.Run("alphanumeric names collide for similar urls", func(t *testing.T) {
+		assert.Equal(t, AlphanumericNamer{}.Name(first), "http_localhost_com_recipes_pan_cakes")
+		assert.Equal(t, AlphanumericNamer{}.Name(first), AlphanumericNamer{}.Name(second))
+	})
+
+	t.Run("md5 names differ for similar urls", func(t *testing.T) {
+		name := MD5Namer{}.Name(first)
+		assert.Equal(t, len(name), 32)
+		assert.Equal(t, name, MD5Namer{}.Name(first))
+		assert.NotEqual(t, name, MD5Namer{}.Name(second))
+	})
+
+	t.Run("path names mirror the url", func(t *testing.T) {
+		tests := []struct {
+			rawURL string
+			want   string
+		}{
+			{rawURL: "http://localhost.com", want: "localhost.com/index.html"},
+			{rawURL: "http://localhost.com/", want: "localhost.com/index.html"},
+			{rawURL: "http://localhost.com/blog/post", want: "localhost.com/blog/post/index.html"},
+			{rawURL: "http://localhost.com:8080/blog//post/", want: "localhost.com_8080/blog/post/index.html"},
+			{rawURL: "http://localhost.com/search?q=soup&page=2", want: "localhost.com/search/index_q_soup_page_2.html"},
+			{rawURL: "http://localhost.com/a b/%2E%2E/c", want: "localhost.com/a_b/_/c/index.html"},
+			{rawURL: "http://localhost.com/blog/index.html", want: "localhost.com/blog/index.html"},
+			{rawURL: "http://localhost.com/blog/post.php?id=1", want: "localhost.com/blog/post_id_1.php"},
+			{rawURL: "http://localhost.com/v1.2/", want: "localhost.com/v1.2/index.html"},
+		}
+
+		for _, tt := range tests {
+			assert.Equal(t, PathNamer{}.Name(tt.rawURL), tt.want)
+		}
+	})
+}
+
+func TestPathNamer_FileStorage(t *testing.T) {
+	storage, err := NewFileStorage(t.TempDir())
+	assert.Nil(t, err)
+
+	for _, rawURL := range []string{
+		"http://localhost.com/blog",
+		"http://localhost.com/blog/index.html",
+		"http://localhost.com/blog/post",
+		"http://localhost.com/blog/post.html",
+	} {
+		assert.Nil(t, storage.Write(PathNamer{}.Name(rawURL), strings.NewReader(rawURL)))
+	}
+}
+
+func TestWithFileNamer(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		dir        = t.TempDir()
+	)
+
+	httpClient.Request(link, func() (int, string) {
+		return http.StatusOK, `<a href="/blog">Blog</a>`
+	})
+
+	httpClient.Request(link+"/blog", func() (int, string) {
+		return http.StatusOK, `<a href="/blog/post">Post</a>`
+	})
+
+	httpClient.Request(link+"/blog/post", func() (int, string) {
+		return http.StatusOK, `<p>Post</p>`
+	})
+
+	crawler, err := NewCrawler(httpClient, dir, WithFileNamer(PathNamer{}))
+	assert.Nil(t, err)
+
+	report, err := crawler.Start(context.Background(), link, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, len(report.VisitedURLs), 3)
+
+	for file, want := range map[string]string{
+		"localhost.com/index.html":           `<a href="/blog">Blog</a>`,
+		"localhost.com/blog/index.html":      `<a href="/blog/post">Post</a>`,
+		"localhost.com/blog/post/index.html": `<p>Post</p>`,
+	} {
+		contents, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		assert.Nil(t, err)
+		assert.Equal(t, string(contents), want)
+	}
+}
@@ -0,0 +1,808 @@
+package crawler
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Option configures a Crawler. Options are applied in order by New and NewCrawler
+// and return an error when given an invalid value.
+type Option func(c *Crawler) error
+
+// WithMaxConcurrent sets the maximum number of links crawled in parallel.
+func WithMaxConcurrent(n int) Option {
+	return func(c *Crawler) error {
+		if n < 1 {
+			return fmt.Errorf("max concurrent must be at least 1, got %d", n)
+		}
+
+		c.maxConcurrent = n
+		return nil
+	}
+}
+
+// WithTimeout sets the request timeout of the default HTTP client.
+// It has no effect when a custom client is provided with WithHTTPClient.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Crawler) error {
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive, got %s", d)
+		}
+
+		c.timeout = d
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Crawler) error {
+		if ua == "" {
+			return errors.New("user agent must not be empty")
+		}
+
+		c.userAgent = ua
+		return nil
+	}
+}
+
+// WithDestinationDir sets the directory where fetched pages will be saved.
+func WithDestinationDir(dir string) Option {
+	return func(c *Crawler) error {
+		if dir == "" {
+			return errors.New("destination directory must not be empty")
+		}
+
+		c.destinationDir = dir
+		return nil
+	}
+}
+
+// WithHTTPClient sets the client used to make HTTP requests.
+func WithHTTPClient(client HttpClient) Option {
+	return func(c *Crawler) error {
+		if client == nil {
+			return errors.New("http client must not be nil")
+		}
+
+		c.httpClient = client
+		return nil
+	}
+}
+
+// WithRobots configures robots.txt handling. A zero CacheExpiry uses DefaultRobotsCacheExpiry.
+func WithRobots(cfg RobotsConfig) Option {
+	return func(c *Crawler) error {
+		if cfg.CacheExpiry < 0 {
+			return fmt.Errorf("robots cache expiry must not be negative, got %s", cfg.CacheExpiry)
+		}
+
+		if cfg.CacheExpiry == 0 {
+			cfg.CacheExpiry = DefaultRobotsCacheExpiry
+		}
+
+		c.robots = cfg
+		return nil
+	}
+}
+
+// WithFetchSitemap enables seeding the crawl with the URLs listed in the host's
+// sitemap.xml and sitemap_index.xml.
+func WithFetchSitemap(fetch bool) Option {
+	return func(c *Crawler) error {
+		c.fetchSitemap = fetch
+		return nil
+	}
+}
+
+// WithDomainRateLimit limits requests to host to rps requests per second with the given burst.
+func WithDomainRateLimit(host string, rps float64, burst int) Option {
+	return func(c *Crawler) error {
+		if host == "" {
+			return errors.New("rate limit host must not be empty")
+		}
+
+		if err := validateRateLimit(rps, burst); err != nil {
+			return err
+		}
+
+		c.rateLimiter.SetLimit(host, rps, burst)
+		return nil
+	}
+}
+
+// WithDefaultRateLimit limits requests to every host without a host-specific limit
+// to rps requests per second with the given burst.
+func WithDefaultRateLimit(rps float64, burst int) Option {
+	return func(c *Crawler) error {
+		if err := validateRateLimit(rps, burst); err != nil {
+			return err
+		}
+
+		c.rateLimiter.SetDefaultLimit(rps, burst)
+		return nil
+	}
+}
+
+// validateRateLimit checks that a rate limit allows at least one request.
+func validateRateLimit(rps float64, burst int) error {
+	if rps <= 0 {
+		return fmt.Errorf("rate limit must be positive, got %v", rps)
+	}
+
+	if burst < 1 {
+		return fmt.Errorf("rate limit burst must be at least 1, got %d", burst)
+	}
+
+	return nil
+}
+
+// WithCookieJar stores cookies set by responses in jar and sends them with subsequent
+// requests. A nil jar uses a new in-memory jar from net/http/cookiejar.
+func WithCookieJar(jar http.CookieJar) Option {
+	return func(c *Crawler) error {
+		if jar == nil {
+			var err error
+			if jar, err = cookiejar.New(nil); err != nil {
+				return fmt.Errorf("create cookie jar: %w", err)
+			}
+		}
+
+		c.cookieJar = jar
+		return nil
+	}
+}
+
+// WithCheckpoint loads the crawl state from the checkpoint file at path when Start is
+// called and saves it there periodically and when Start returns.
+func WithCheckpoint(path string) Option {
+	return func(c *Crawler) error {
+		if path == "" {
+			return errors.New("checkpoint path must not be empty")
+		}
+
+		c.checkpointPath = path
+		return nil
+	}
+}
+
+// WithCheckpointInterval sets how often a checkpoint is saved while crawling.
+func WithCheckpointInterval(d time.Duration) Option {
+	return func(c *Crawler) error {
+		if d <= 0 {
+			return fmt.Errorf("checkpoint interval must be positive, got %s", d)
+		}
+
+		c.checkpointInterval = d
+		return nil
+	}
+}
+
+// WithOnPageFetched calls fn with each page fetched during a crawl. Each call runs on its
+// own goroutine and the crawl waits for it to return, so fn must be safe for concurrent
+// use. Once the crawl's context is done, fn is no longer called and the crawl stops
+// waiting, so a call may still be running after Start returns.
+func WithOnPageFetched(fn func(PageResult)) Option {
+	return func(c *Crawler) error {
+		if fn == nil {
+			return errors.New("page fetched callback must not be nil")
+		}
+
+		c.onPageFetched = fn
+		return nil
+	}
+}
+
+// WithOnLinkFound calls fn with every link found on a fetched page. Each call runs on its
+// own goroutine and the crawl waits for it to return, so fn must be safe for concurrent
+// use. Once the crawl's context is done, fn is no longer called and the crawl stops
+// waiting, so a call may still be running after Start returns.
+func WithOnLinkFound(fn func(from, to string)) Option {
+	return func(c *Crawler) error {
+		if fn == nil {
+			return errors.New("link found callback must not be nil")
+		}
+
+		c.onLinkFound = fn
+		return nil
+	}
+}
+
+// WithIncludePattern only visits links whose URL matches pattern. When given multiple
+// times, a link must match at least one of the patterns.
+func WithIncludePattern(pattern string) Option {
+	return func(c *Crawler) error {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("compile include pattern: %w", err)
+		}
+
+		c.includePatterns = append(c.includePatterns, re)
+		return nil
+	}
+}
+
+// WithExcludePattern skips links whose URL matches pattern. It can be given multiple times.
+func WithExcludePattern(pattern string) Option {
+	return func(c *Crawler) error {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("compile exclude pattern: %w", err)
+		}
+
+		c.excludePatterns = append(c.excludePatterns, re)
+		return nil
+	}
+}
+
+// WithDomainWhitelist only follows links to the given domains, replacing the default rule
+// that links must be children of the page they are found on. Domains are matched case
+// insensitively and without their "www." prefix.
+func WithDomainWhitelist(domains ...string) Option {
+	return func(c *Crawler) error {
+		if c.domainWhitelist == nil {
+			c.domainWhitelist = make(map[string]struct{})
+		}
+
+		for _, domain := range domains {
+			if domain == "" {
+				return errors.New("whitelisted domain must not be empty")
+			}
+
+			c.domainWhitelist[normalizeDomain(domain)] = struct{}{}
+		}
+
+		return nil
+	}
+}
+
+// WithDomainBlacklist never follows links to the given domains. Domains are matched case
+// insensitively and without their "www." prefix.
+func WithDomainBlacklist(domains ...string) Option {
+	return func(c *Crawler) error {
+		if c.domainBlacklist == nil {
+			c.domainBlacklist = make(map[string]struct{})
+		}
+
+		for _, domain := range domains {
+			if domain == "" {
+				return errors.New("blacklisted domain must not be empty")
+			}
+
+			c.domainBlacklist[normalizeDomain(domain)] = struct{}{}
+		}
+
+		return nil
+	}
+}
+
+// WithRetry retries downloads that fail with a timeout or a 5xx status, making at most
+// maxAttempts attempts in total. The delay before each retry starts at baseDelay, doubles
+// after every attempt up to maxDelay, and has ±10% jitter.
+func WithRetry(maxAttempts int, baseDelay time.Duration, maxDelay time.Duration) Option {
+	return func(c *Crawler) error {
+		if maxAttempts < 1 {
+			return fmt.Errorf("retry attempts must be at least 1, got %d", maxAttempts)
+		}
+
+		if baseDelay <= 0 {
+			return fmt.Errorf("retry base delay must be positive, got %s", baseDelay)
+		}
+
+		if maxDelay < baseDelay {
+			return fmt.Errorf("retry max delay %s must not be less than base delay %s", maxDelay, baseDelay)
+		}
+
+		c.retryAttempts = maxAttempts
+		c.retryBaseDelay = baseDelay
+		c.retryMaxDelay = maxDelay
+		return nil
+	}
+}
+
+// WithStorage sets the storage used to cache fetched pages. The destination directory is
+// not used when a storage is provided.
+func WithStorage(storage Storage) Option {
+	return func(c *Crawler) error {
+		if storage == nil {
+			return errors.New("storage must not be nil")
+		}
+
+		c.storage = storage
+		return nil
+	}
+}
+
+// WithLinkGraph records the links found on every fetched page in g.
+func WithLinkGraph(g *LinkGraph) Option {
+	return func(c *Crawler) error {
+		if g == nil {
+			return errors.New("link graph must not be nil")
+		}
+
+		c.linkGraph = g
+		return nil
+	}
+}
+
+// WithProgressChannel sends progress events to ch while Start runs. Events are queued
+// without blocking the crawl and dropped when the queue is full; see
+// WithProgressBufferSize. Start never waits for events to be read: the queued events that
+// do not fit in ch when it returns are dropped. ch is never closed.
+func WithProgressChannel(ch chan<- ProgressEvent) Option {
+	return func(c *Crawler) error {
+		if ch == nil {
+			return errors.New("progress channel must not be nil")
+		}
+
+		c.progressCh = ch
+		return nil
+	}
+}
+
+// WithProgressBufferSize sets how many progress events are queued for a slow reader of the
+// progress channel before new events are dropped. It defaults to DefaultProgressBufferSize.
+func WithProgressBufferSize(n int) Option {
+	return func(c *Crawler) error {
+		if n < 1 {
+			return fmt.Errorf("progress buffer size must be at least 1, got %d", n)
+		}
+
+		c.progressBufferSize = n
+		return nil
+	}
+}
+
+// WithFollowMetaRefresh treats the target of a <meta http-equiv="refresh"> tag as a link.
+func WithFollowMetaRefresh(follow bool) Option {
+	return func(c *Crawler) error {
+		c.followMetaRefresh = follow
+		return nil
+	}
+}
+
+// WithRespectNoFollow skips links marked rel="nofollow" and all links on pages whose
+// robots meta tag includes nofollow.
+func WithRespectNoFollow(respect bool) Option {
+	return func(c *Crawler) error {
+		c.respectNoFollow = respect
+		return nil
+	}
+}
+
+// WithUseCanonical marks the URL declared by a page's <link rel="canonical"> tag as
+// visited along with the crawled URL.
+func WithUseCanonical(use bool) Option {
+	return func(c *Crawler) error {
+		c.useCanonical = use
+		return nil
+	}
+}
+
+// WithMaxPages limits the number of pages visited during a crawl. Zero means no limit.
+func WithMaxPages(n int) Option {
+	return func(c *Crawler) error {
+		if n < 0 {
+			return fmt.Errorf("max pages must not be negative, got %d", n)
+		}
+
+		c.maxPages = n
+		return nil
+	}
+}
+
+// WithMaxBytes limits the number of bytes downloaded during a crawl. Downloads already in
+// progress when the limit is reached are completed, so the total may exceed the limit by
+// up to the size of those pages. Zero means no limit.
+func WithMaxBytes(n int64) Option {
+	return func(c *Crawler) error {
+		if n < 0 {
+			return fmt.Errorf("max bytes must not be negative, got %d", n)
+		}
+
+		c.maxBytes = n
+		return nil
+	}
+}
+
+// WithTraversal sets the order in which discovered links are crawled.
+func WithTraversal(strategy TraversalStrategy) Option {
+	return func(c *Crawler) error {
+		switch strategy {
+		case TraversalDFS, TraversalBFS:
+			c.traversal = strategy
+			return nil
+		default:
+			return fmt.Errorf("unknown traversal strategy %q", strategy)
+		}
+	}
+}
+
+// WithResetClearCache makes Reset delete the stored pages and their cache metadata.
+//
+// With the default file storage, only the files the storage recorded writing are deleted,
+// so other files in the destination directory, such as a checkpoint or a manifest, are
+// kept. A custom Storage is cleared however its Clear method does it, which may delete
+// everything it holds.
+func WithResetClearCache(clearCache bool) Option {
+	return func(c *Crawler) error {
+		c.resetClearCache = clearCache
+		return nil
+	}
+}
+
+// WithDeduplicateContent skips saving downloaded pages whose content, compared by SHA-256
+// hash, matches a page already downloaded from another URL. An alias to the stored page is
+// saved in their place, so that they are not downloaded again.
+func WithDeduplicateContent(deduplicate bool) Option {
+	return func(c *Crawler) error {
+		c.deduplicateContent = deduplicate
+		return nil
+	}
+}
+
+// WithLogger sets the logger used to report crawl activity. Without it, slog.Default() is used.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Crawler) error {
+		if logger == nil {
+			return errors.New("logger must not be nil")
+		}
+
+		c.logger = logger
+		return nil
+	}
+}
+
+// WithProxy sends the requests of the default HTTP client through the proxy at proxyURL,
+// which must use the http, https or socks5 scheme. An empty proxyURL uses the proxy
+// configured by the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+// The proxy is ignored when a custom HTTP client is used.
+func WithProxy(proxyURL string) Option {
+	return func(c *Crawler) error {
+		if proxyURL == "" {
+			c.proxy = http.ProxyFromEnvironment
+			return nil
+		}
+
+		parsedURL, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("parse proxy url: %w", err)
+		}
+
+		switch parsedURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("unsupported proxy scheme %q", parsedURL.Scheme)
+		}
+
+		if parsedURL.Host == "" {
+			return fmt.Errorf("proxy url must include a host: %q", proxyURL)
+		}
+
+		c.proxy = http.ProxyURL(parsedURL)
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the default HTTP client. The configuration
+// is cloned, and it is ignored when a custom HTTP client is used.
+func WithTLSConfig(tlsCfg *tls.Config) Option {
+	return func(c *Crawler) error {
+		if tlsCfg == nil {
+			return errors.New("tls config must not be nil")
+		}
+
+		c.tlsConfig = tlsCfg.Clone()
+		return nil
+	}
+}
+
+// WithInsecureSkipVerify disables the verification of server certificates by the default
+// HTTP client, such as for sites with self-signed certificates. It is applied on top of
+// any TLS configuration set before it and ignored when a custom HTTP client is used.
+func WithInsecureSkipVerify(skip bool) Option {
+	return func(c *Crawler) error {
+		if c.tlsConfig == nil {
+			c.tlsConfig = &tls.Config{}
+		}
+
+		c.tlsConfig.InsecureSkipVerify = skip
+		return nil
+	}
+}
+
+// WithExtractText sets the visible text of each fetched page on its PageResult. It is
+// disabled by default because it parses every page a second time.
+func WithExtractText(extract bool) Option {
+	return func(c *Crawler) error {
+		c.extractText = extract
+		return nil
+	}
+}
+
+// WithSaveHeaders saves the response headers of each downloaded page in a sidecar next to
+// the page, named after its storage key with a ".headers.json" suffix. The headers are
+// loaded with cached pages and set on their PageResult.
+func WithSaveHeaders(save bool) Option {
+	return func(c *Crawler) error {
+		c.saveHeaders = save
+		return nil
+	}
+}
+
+// WithExtractAssets downloads the images, scripts, stylesheets and media sources used by
+// each fetched page. Assets are saved like pages but are not parsed for links.
+func WithExtractAssets(extract bool) Option {
+	return func(c *Crawler) error {
+		c.extractAssets = extract
+		return nil
+	}
+}
+
+// WithDownloadExternalAssets includes assets hosted on other domains when assets are
+// extracted. Blacklisted domains are still skipped.
+func WithDownloadExternalAssets(download bool) Option {
+	return func(c *Crawler) error {
+		c.downloadExternalAssets = download
+		return nil
+	}
+}
+
+// WithExtractFormActions treats the action of each <form method="get"> as a link.
+func WithExtractFormActions(extract bool) Option {
+	return func(c *Crawler) error {
+		c.extractFormActions = extract
+		return nil
+	}
+}
+
+// WithExtractPostForms also treats the action of each <form method="post"> as a link when
+// form actions are extracted.
+func WithExtractPostForms(extract bool) Option {
+	return func(c *Crawler) error {
+		c.extractPostForms = extract
+		return nil
+	}
+}
+
+// WithFollowPagination treats the targets of <link rel="next"> and <link rel="prev"> tags
+// as links.
+func WithFollowPagination(follow bool) Option {
+	return func(c *Crawler) error {
+		c.followPagination = follow
+		return nil
+	}
+}
+
+// WithFileNamer sets how the storage keys of fetched pages are named. Without it,
+// AlphanumericNamer is used.
+func WithFileNamer(namer FileNamer) Option {
+	return func(c *Crawler) error {
+		if namer == nil {
+			return errors.New("file namer must not be nil")
+		}
+
+		c.fileNamer = namer
+		return nil
+	}
+}
+
+// WithMemoryOnly keeps fetched pages in a MemoryStorage instead of saving them to disk.
+// The destination directory is ignored and never created. A storage set with WithStorage
+// takes precedence.
+func WithMemoryOnly(memoryOnly bool) Option {
+	return func(c *Crawler) error {
+		c.memoryOnly = memoryOnly
+		return nil
+	}
+}
+
+// WithTransportConfig sets the per-phase timeouts of the default HTTP client. It is
+// ignored when a custom HTTP client is used.
+func WithTransportConfig(tc TransportConfig) Option {
+	return func(c *Crawler) error {
+		timeouts := []struct {
+			name string
+			d    time.Duration
+		}{
+			{name: "dial", d: tc.DialTimeout},
+			{name: "tls handshake", d: tc.TLSHandshakeTimeout},
+			{name: "response header", d: tc.ResponseHeaderTimeout},
+			{name: "expect continue", d: tc.ExpectContinueTimeout},
+		}
+
+		for _, timeout := range timeouts {
+			if timeout.d < 0 {
+				return fmt.Errorf("%s timeout must not be negative, got %s", timeout.name, timeout.d)
+			}
+		}
+
+		c.transportConfig = &tc
+		return nil
+	}
+}
+
+// WithBandwidthLimit limits the rate at which response bodies are read to bytesPerSecond,
+// shared by all concurrent downloads.
+func WithBandwidthLimit(bytesPerSecond int64) Option {
+	return func(c *Crawler) error {
+		if bytesPerSecond <= 0 {
+			return fmt.Errorf("bandwidth limit must be positive, got %d", bytesPerSecond)
+		}
+
+		c.bandwidthLimiter = newBandwidthLimiter(bytesPerSecond)
+		return nil
+	}
+}
+
+// WithAcceptLanguage sends every request with the Accept-Language header set to lang,
+// such as "sw, en;q=0.8".
+func WithAcceptLanguage(lang string) Option {
+	return func(c *Crawler) error {
+		if lang == "" {
+			return errors.New("accept language must not be empty")
+		}
+
+		c.setHeader("Accept-Language", lang)
+		return nil
+	}
+}
+
+// WithAcceptEncoding sends every request with the Accept-Encoding header set to enc, such
+// as "gzip, deflate". Responses encoded with gzip or deflate are decompressed before they
+// are saved. Only the gzip, deflate and identity encodings, with optional quality values,
+// are accepted, since responses in any other encoding could not be decompressed.
+func WithAcceptEncoding(enc string) Option {
+	return func(c *Crawler) error {
+		if enc == "" {
+			return errors.New("accept encoding must not be empty")
+		}
+
+		for _, coding := range strings.Split(enc, ",") {
+			name, _, _ := strings.Cut(coding, ";")
+			if !slices.Contains(supportedEncodings, strings.ToLower(strings.TrimSpace(name))) {
+				return fmt.Errorf("unsupported accept encoding %q", strings.TrimSpace(coding))
+			}
+		}
+
+		c.setHeader("Accept-Encoding", enc)
+		return nil
+	}
+}
+
+// WithRequestCompression asks servers for compressed responses by sending every request
+// with "Accept-Encoding: gzip, deflate". Responses encoded with gzip or deflate are
+// decompressed whether or not compression was requested.
+func WithRequestCompression(compress bool) Option {
+	return func(c *Crawler) error {
+		if compress {
+			c.setHeader("Accept-Encoding", "gzip, deflate")
+		}
+
+		return nil
+	}
+}
+
+// WithCrawlTimeout limits the duration of a whole crawl to d. Pages already being fetched
+// when the timeout expires are abandoned and the report is marked as interrupted.
+func WithCrawlTimeout(d time.Duration) Option {
+	return func(c *Crawler) error {
+		if d <= 0 {
+			return fmt.Errorf("crawl timeout must be positive, got %s", d)
+		}
+
+		c.crawlTimeout = d
+		return nil
+	}
+}
+
+// WithAllowedSchemes only follows and visits URLs with one of the given schemes, such as
+// "https" alone for a crawl that must not leave HTTPS. It replaces the default of "http"
+// and "https". Schemes are matched case insensitively.
+func WithAllowedSchemes(schemes ...string) Option {
+	return func(c *Crawler) error {
+		if len(schemes) == 0 {
+			return errors.New("at least one scheme is required")
+		}
+
+		c.allowedSchemes = make(map[string]struct{}, len(schemes))
+
+		for _, scheme := range schemes {
+			if scheme == "" {
+				return errors.New("allowed scheme must not be empty")
+			}
+
+			c.allowedSchemes[strings.ToLower(scheme)] = struct{}{}
+		}
+
+		return nil
+	}
+}
+
+// WithMaxURLLength skips links whose URL, with its query removed, is longer than n
+// characters.
+func WithMaxURLLength(n int) Option {
+	return func(c *Crawler) error {
+		if n <= 0 {
+			return fmt.Errorf("max url length must be positive, got %d", n)
+		}
+
+		c.maxURLLength = n
+		return nil
+	}
+}
+
+// WithPolitenessDelay waits at least d between any two requests to the same host, like the
+// Crawl-delay directive of robots.txt. The delay applies in addition to any rate limit.
+func WithPolitenessDelay(d time.Duration) Option {
+	return func(c *Crawler) error {
+		if d <= 0 {
+			return fmt.Errorf("politeness delay must be positive, got %s", d)
+		}
+
+		c.politenessDelay = d
+		return nil
+	}
+}
+
+// WithMaxConnsPerHost limits the number of connections the default HTTP client opens to
+// each host, including connections in use and idle ones. Requests beyond the limit wait
+// for a connection to be available. It has no effect when a custom HTTP client is used.
+func WithMaxConnsPerHost(n int) Option {
+	return func(c *Crawler) error {
+		if n <= 0 {
+			return fmt.Errorf("max conns per host must be positive, got %d", n)
+		}
+
+		c.maxConnsPerHost = n
+		return nil
+	}
+}
+
+// WithMaxIdleConnsPerHost sets the number of idle connections the default HTTP client keeps
+// open to each host for reuse. It has no effect when a custom HTTP client is used.
+func WithMaxIdleConnsPerHost(n int) Option {
+	return func(c *Crawler) error {
+		if n <= 0 {
+			return fmt.Errorf("max idle conns per host must be positive, got %d", n)
+		}
+
+		c.maxIdleConnsPerHost = n
+		return nil
+	}
+}
+
+// WithIdleConnTimeout closes the idle connections of the default HTTP client after d. It
+// has no effect when a custom HTTP client is used.
+func WithIdleConnTimeout(d time.Duration) Option {
+	return func(c *Crawler) error {
+		if d <= 0 {
+			return fmt.Errorf("idle conn timeout must be positive, got %s", d)
+		}
+
+		c.idleConnTimeout = d
+		return nil
+	}
+}
+
+// WithManifest saves a JSON manifest of the fetched pages to path when a crawl completes,
+// listing the local file each page is stored in. See ManifestEntry.
+func WithManifest(path string) Option {
+	return func(c *Crawler) error {
+		if path == "" {
+			return errors.New("manifest path must not be empty")
+		}
+
+		c.manifestPath = path
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+package crawler
+
+import (
+	"context"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("applies defaults", func(t *testing.T) {
+		dir := t.TempDir()
+
+		crawler, err := New(WithDestinationDir(dir))
+		assert.Nil(t, err)
+		assert.Equal(t, crawler.destinationDir, dir)
+		assert.Equal(t, crawler.timeout, DefaultTimeout)
+		assert.True(t, crawler.maxConcurrent >= 1)
+		assert.NotNil(t, crawler.httpClient)
+	})
+
+	t.Run("applies options", func(t *testing.T) {
+		httpClient := testutil.NewTestHttpClient()
+
+		crawler, err := NewCrawler(nil, t.TempDir(),
+			WithHTTPClient(httpClient),
+			WithMaxConcurrent(2),
+			WithTimeout(time.Second),
+			WithUserAgent("kitchen-crawler/1.0"),
+		)
+		assert.Nil(t, err)
+		assert.Equal[HttpClient](t, crawler.httpClient, httpClient)
+		assert.Equal(t, crawler.maxConcurrent, 2)
+		assert.Equal(t, crawler.timeout, time.Second)
+		assert.Equal(t, crawler.userAgent, "kitchen-crawler/1.0")
+	})
+
+	t.Run("uses the timeout for the default client", func(t *testing.T) {
+		crawler, err := New(WithDestinationDir(t.TempDir()), WithTimeout(time.Second))
+		assert.Nil(t, err)
+
+		client, ok := crawler.httpClient.(*http.Client)
+		assert.True(t, ok)
+		assert.Equal(t, client.Timeout, time.Second)
+	})
+
+	t.Run("rejects invalid options", func(t *testing.T) {
+		tests := []struct {
+			name string
+			opt  Option
+		}{
+			{name: "max concurrent", opt: WithMaxConcurrent(0)},
+			{name: "timeout", opt: WithTimeout(-time.Second)},
+			{name: "user agent", opt: WithUserAgent("")},
+			{name: "destination dir", opt: WithDestinationDir("")},
+			{name: "http client", opt: WithHTTPClient(nil)},
+			{name: "max pages", opt: WithMaxPages(-1)},
+			{name: "max bytes", opt: WithMaxBytes(-1)},
+			{name: "traversal", opt: WithTraversal("random")},
+			{name: "logger", opt: WithLogger(nil)},
+			{name: "tls config", opt: WithTLSConfig(nil)},
+			{name: "file namer", opt: WithFileNamer(nil)},
+			{name: "transport config", opt: WithTransportConfig(TransportConfig{DialTimeout: -time.Second})},
+			{name: "bandwidth limit", opt: WithBandwidthLimit(0)},
+			{name: "accept language", opt: WithAcceptLanguage("")},
+			{name: "accept encoding", opt: WithAcceptEncoding("")},
+			{name: "unsupported accept encoding", opt: WithAcceptEncoding("gzip, br")},
+			{name: "wildcard accept encoding", opt: WithAcceptEncoding("*")},
+			{name: "crawl timeout", opt: WithCrawlTimeout(0)},
+			{name: "allowed schemes", opt: WithAllowedSchemes()},
+			{name: "empty allowed scheme", opt: WithAllowedSchemes("https", "")},
+			{name: "max url length", opt: WithMaxURLLength(0)},
+			{name: "politeness delay", opt: WithPolitenessDelay(-time.Second)},
+			{name: "max conns per host", opt: WithMaxConnsPerHost(0)},
+			{name: "max idle conns per host", opt: WithMaxIdleConnsPerHost(-1)},
+			{name: "idle conn timeout", opt: WithIdleConnTimeout(0)},
+			{name: "manifest", opt: WithManifest("")},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				crawler, err := New(WithDestinationDir(t.TempDir()), tt.opt)
+				assert.NotNil(t, err)
+				assert.Nil(t, crawler)
+			})
+		}
+	})
+}
+
+func TestWithUserAgent(t *testing.T) {
+	var userAgent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+	}))
+	defer server.Close()
+
+	crawler, err := New(WithDestinationDir(t.TempDir()), WithUserAgent("kitchen-crawler/1.0"))
+	assert.Nil(t, err)
+
+	_, err = crawler.Fetch(context.Background(), server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, userAgent, "kitchen-crawler/1.0")
+}
@@ -0,0 +1,57 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+)
+
+// Pause stops the crawl from starting new requests until Resume is called. Requests
+// already in flight are allowed to finish. A crawler paused before Start begins does not
+// make any request until it is resumed.
+//
+// An error is returned when the crawler is already paused.
+func (c *Crawler) Pause() error {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+
+	if c.pauseCh != nil {
+		return errors.New("pause crawler: already paused")
+	}
+
+	c.pauseCh = make(chan struct{})
+	return nil
+}
+
+// Resume lets a paused crawl continue.
+//
+// An error is returned when the crawler is not paused.
+func (c *Crawler) Resume() error {
+	c.pauseMu.Lock()
+	defer c.pauseMu.Unlock()
+
+	if c.pauseCh == nil {
+		return errors.New("resume crawler: not paused")
+	}
+
+	close(c.pauseCh)
+	c.pauseCh = nil
+	return nil
+}
+
+// waitResumed blocks while the crawler is paused, until it is resumed or ctx is done.
+func (c *Crawler) waitResumed(ctx context.Context) error {
+	c.pauseMu.Lock()
+	pauseCh := c.pauseCh
+	c.pauseMu.Unlock()
+
+	if pauseCh == nil {
+		return nil
+	}
+
+	select {
+	case <-pauseCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,285 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCrawler_PauseResume(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		requests   atomic.Int64
+		crawler    *Crawler
+		home       strings.Builder
+	)
+
+	for i := range 10 {
+		page := fmt.Sprintf("%s/page-%d", link, i)
+		fmt.Fprintf(&home, `<a href="%s">Page %d</a>`, page, i)
+
+		httpClient.Request(page, func() (code int, body string) {
+			// The first page pauses the crawl while its request is in flight
+			if requests.Add(1) == 2 {
+				assert.Nil(t, crawler.Pause())
+			}
+
+			return http.StatusOK, `<p>Page</p>`
+		})
+	}
+
+	httpClient.Request(link, func() (code int, body string) {
+		requests.Add(1)
+		return http.StatusOK, home.String()
+	})
+
+	t.Run("stops making requests while paused", func(t *testing.T) {
+		var err error
+
+		crawler, err = NewCrawler(httpClient, t.TempDir(), WithMaxConcurrent(1))
+		assert.Nil(t, err)
+
+		done := make(chan CrawlReport)
+		go func() {
+			report, err := crawler.Start(ctx, link, 2)
+			assert.Nil(t, err)
+			done <- report
+		}()
+
+		deadline := time.Now().Add(time.Second)
+		for requests.Load() < 2 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, requests.Load(), int64(2))
+
+		select {
+		case <-done:
+			t.Fatal("crawl finished while paused")
+		default:
+		}
+
+		assert.Nil(t, crawler.Resume())
+
+		select {
+		case report := <-done:
+			assert.Equal(t, len(report.VisitedURLs), 11)
+			assert.False(t, report.Interrupted)
+		case <-time.After(5 * time.Second):
+			t.Fatal("crawl did not finish after resuming")
+		}
+
+		assert.Equal(t, requests.Load(), int64(11))
+	})
+
+	t.Run("stops waiting when the context is canceled", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+		assert.Nil(t, crawler.Pause())
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), 0)
+		assert.True(t, report.Interrupted)
+	})
+
+	t.Run("rejects pausing twice", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		assert.Nil(t, crawler.Pause())
+		assert.NotNil(t, crawler.Pause())
+	})
+
+	t.Run("rejects resuming a crawler that is not paused", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		assert.NotNil(t, crawler.Resume())
+
+		assert.Nil(t, crawler.Pause())
+		assert.Nil(t, crawler.Resume())
+		assert.NotNil(t, crawler.Resume())
+	})
+}
+
+func TestCrawler_PauseAssets(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		fetched    = make(chan struct{})
+		assetHits  atomic.Int64
+		crawler    *Crawler
+	)
+
+	httpClient.Request(link, func() (code int, body string) {
+		// The crawl is paused while the page with the assets is in flight
+		assert.Nil(t, crawler.Pause())
+		close(fetched)
+		return http.StatusOK, `<img src="/a.png"><img src="/b.png">`
+	})
+
+	for _, asset := range []string{"/a.png", "/b.png"} {
+		httpClient.Request(link+asset, func() (code int, body string) {
+			assetHits.Add(1)
+			return http.StatusOK, "png"
+		})
+	}
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(), WithExtractAssets(true))
+	assert.Nil(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		_, err := crawler.Start(context.Background(), link, 1)
+		assert.Nil(t, err)
+	}()
+
+	<-fetched
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, assetHits.Load(), int64(0))
+
+	assert.Nil(t, crawler.Resume())
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("crawl did not finish after resuming")
+	}
+
+	assert.Equal(t, assetHits.Load(), int64(2))
+}
+
+func TestCrawler_PausePoliteness(t *testing.T) {
+	const (
+		numPages = 4
+		delay    = 50 * time.Millisecond
+	)
+
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		fetched    = make(chan struct{})
+		mu         sync.Mutex
+		requests   []time.Time
+		crawler    *Crawler
+		home       strings.Builder
+	)
+
+	for i := range numPages {
+		page := fmt.Sprintf("%s/page-%d", link, i)
+		fmt.Fprintf(&home, `<a href="%s">Page %d</a>`, page, i)
+
+		httpClient.Request(page, func() (code int, body string) {
+			mu.Lock()
+			requests = append(requests, time.Now())
+			mu.Unlock()
+
+			return http.StatusOK, `<p>Page</p>`
+		})
+	}
+
+	httpClient.Request(link, func() (code int, body string) {
+		// The crawl is paused while the page with the links is in flight
+		assert.Nil(t, crawler.Pause())
+		close(fetched)
+		return http.StatusOK, home.String()
+	})
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(), WithMaxConcurrent(numPages), WithPolitenessDelay(delay))
+	assert.Nil(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		report, err := crawler.Start(context.Background(), link, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), numPages+1)
+	}()
+
+	// Pausing for longer than the delays of every queued request would have let them all
+	// start at once on resume if their slots were reserved before the pause
+	<-fetched
+	time.Sleep(numPages * 2 * delay)
+	assert.Nil(t, crawler.Resume())
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("crawl did not finish after resuming")
+	}
+
+	assert.Equal(t, len(requests), numPages)
+
+	for i := 1; i < len(requests); i++ {
+		if gap := requests[i].Sub(requests[i-1]); gap < delay/2 {
+			t.Errorf("request %d started %s after the previous one, want about %s", i, gap, delay)
+		}
+	}
+}
+
+func TestCrawler_PauseSitemap(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		requests   atomic.Int64
+	)
+
+	httpClient.Request(link+"/sitemap.xml", func() (code int, body string) {
+		requests.Add(1)
+		return http.StatusOK, `<urlset><url><loc>http://localhost.com/about</loc></url></urlset>`
+	})
+
+	httpClient.Request(link+"/sitemap_index.xml", func() (code int, body string) {
+		requests.Add(1)
+		return http.StatusNotFound, ""
+	})
+
+	for _, page := range []string{link, link + "/about"} {
+		httpClient.Request(page, func() (code int, body string) {
+			requests.Add(1)
+			return http.StatusOK, `<p>Page</p>`
+		})
+	}
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(), WithFetchSitemap(true))
+	assert.Nil(t, err)
+	assert.Nil(t, crawler.Pause())
+
+	done := make(chan CrawlReport)
+	go func() {
+		report, err := crawler.Start(context.Background(), link, 1)
+		assert.Nil(t, err)
+		done <- report
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, requests.Load(), int64(0))
+
+	assert.Nil(t, crawler.Resume())
+
+	select {
+	case report := <-done:
+		assert.Equal(t, len(report.VisitedURLs), 2)
+	case <-time.After(5 * time.Second):
+		t.Fatal("crawl did not finish after resuming")
+	}
+
+	assert.Equal(t, requests.Load(), int64(4))
+}
@@ -0,0 +1,54 @@
+package crawler
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// waitHost blocks until a request to host is allowed by both the per-host rate limit and
+// the politeness delay, or ctx is done.
+func (c *Crawler) waitHost(ctx context.Context, host string) error {
+	if err := c.rateLimiter.Wait(ctx, host); err != nil {
+		return err
+	}
+
+	return c.waitPoliteness(ctx, host)
+}
+
+// waitPoliteness blocks until the politeness delay has passed since the previous request to
+// host, or ctx is done. The time of the next request to host is reserved before waiting so
+// that concurrent requests to the same host are spaced out as well.
+func (c *Crawler) waitPoliteness(ctx context.Context, host string) error {
+	if c.politenessDelay <= 0 {
+		return nil
+	}
+
+	host = strings.ToLower(host)
+
+	c.politenessMu.Lock()
+	now := time.Now()
+	next := now
+
+	if last, ok := c.lastRequestTime[host]; ok && last.Add(c.politenessDelay).After(now) {
+		next = last.Add(c.politenessDelay)
+	}
+
+	c.lastRequestTime[host] = next
+	c.politenessMu.Unlock()
+
+	wait := next.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
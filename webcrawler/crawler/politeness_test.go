@@ -0,0 +1,118 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithPolitenessDelay(t *testing.T) {
+	const (
+		numPages = 6
+		delay    = 50 * time.Millisecond
+	)
+
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		home       strings.Builder
+	)
+
+	for i := range numPages - 1 {
+		page := fmt.Sprintf("%s/page-%d", link, i)
+		fmt.Fprintf(&home, `<a href="%s">Page %d</a>`, page, i)
+
+		httpClient.Request(page, func() (code int, body string) {
+			return http.StatusOK, `<p>Page</p>`
+		})
+	}
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, home.String()
+	})
+
+	t.Run("waits between requests to the same host", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithPolitenessDelay(delay))
+		assert.Nil(t, err)
+
+		startedAt := time.Now()
+
+		report, err := crawler.Start(ctx, link, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, len(report.VisitedURLs), numPages)
+
+		elapsed := time.Since(startedAt)
+		if elapsed < (numPages-1)*delay {
+			t.Errorf("elapsed %s, want at least %s", elapsed, (numPages-1)*delay)
+		}
+	})
+
+	t.Run("waits between asset requests", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			requests []time.Time
+			client   = testutil.NewTestHttpClient()
+		)
+
+		record := func(body string) func() (int, string) {
+			return func() (int, string) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				requests = append(requests, time.Now())
+				return http.StatusOK, body
+			}
+		}
+
+		client.Request(link, record(`<img src="/a.png"><img src="/b.png"><img src="/c.png">`))
+		for _, asset := range []string{"/a.png", "/b.png", "/c.png"} {
+			client.Request(link+asset, record("png"))
+		}
+
+		crawler, err := NewCrawler(client, t.TempDir(), WithPolitenessDelay(delay), WithExtractAssets(true))
+		assert.Nil(t, err)
+
+		_, err = crawler.Start(ctx, link, 1)
+		assert.Nil(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, len(requests), 4)
+
+		elapsed := requests[len(requests)-1].Sub(requests[0])
+		if elapsed < 3*delay {
+			t.Errorf("requests spanned %s, want at least %s", elapsed, 3*delay)
+		}
+	})
+
+	t.Run("does not wait between requests to different hosts", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithPolitenessDelay(time.Minute))
+		assert.Nil(t, err)
+
+		startedAt := time.Now()
+
+		assert.Nil(t, crawler.waitPoliteness(ctx, "localhost.com"))
+		assert.Nil(t, crawler.waitPoliteness(ctx, "example.com"))
+		assert.True(t, time.Since(startedAt) < time.Second)
+	})
+
+	t.Run("stops waiting when the context is done", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithPolitenessDelay(time.Minute))
+		assert.Nil(t, err)
+
+		assert.Nil(t, crawler.waitPoliteness(ctx, "localhost.com"))
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, crawler.waitPoliteness(ctx, "localhost.com"), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,130 @@
+package crawler
+
+import (
+	"time"
+)
+
+// DefaultProgressBufferSize is the number of progress events queued before new events
+// are dropped when no buffer size is configured.
+const DefaultProgressBufferSize = 100
+
+// ProgressEventType identifies what happened in a ProgressEvent.
+type ProgressEventType string
+
+const (
+	// ProgressFetched is emitted when a page is fetched.
+	ProgressFetched ProgressEventType = "fetched"
+	// ProgressSkipped is emitted when a page is skipped, such as when robots.txt disallows it.
+	ProgressSkipped ProgressEventType = "skipped"
+	// ProgressError is emitted when a page cannot be fetched.
+	ProgressError ProgressEventType = "error"
+	// ProgressLinkFound is emitted for every link found on a fetched page.
+	ProgressLinkFound ProgressEventType = "link-found"
+)
+
+// ProgressEvent reports the progress of a running crawl.
+type ProgressEvent struct {
+	Type ProgressEventType
+	URL  string
+	// Depth is the number of links followed from the starting URL to reach URL.
+	Depth int
+	// Elapsed is the time since the crawl started.
+	Elapsed time.Duration
+	// VisitedCount is the number of pages visited when the event was emitted.
+	VisitedCount int
+}
+
+// emitProgress queues a progress event without blocking. The event is dropped when the
+// queue is full or no progress channel is configured. Outside of Start, events are sent
+// to the progress channel directly and dropped when it is full.
+func (c *Crawler) emitProgress(eventType ProgressEventType, rawURL string, depth int) {
+	if c.progressCh == nil {
+		return
+	}
+
+	c.mu.RLock()
+	visited := len(c.visitedPages)
+	c.mu.RUnlock()
+
+	event := ProgressEvent{
+		Type:         eventType,
+		URL:          rawURL,
+		Depth:        depth,
+		Elapsed:      time.Since(c.startedAt),
+		VisitedCount: visited,
+	}
+
+	var events chan<- ProgressEvent = c.progressEvents
+	if events == nil {
+		events = c.progressCh
+	}
+
+	select {
+	case events <- event:
+	default:
+	}
+}
+
+// startProgress starts forwarding queued progress events to the progress channel. The
+// returned function stops forwarding without waiting for the progress channel to be read:
+// the queued events that fit in the progress channel are sent and the rest are dropped.
+// No event is sent once it returns.
+func (c *Crawler) startProgress() (stop func()) {
+	if c.progressCh == nil {
+		return func() {}
+	}
+
+	var (
+		events = make(chan ProgressEvent, c.progressBufferSize)
+		done   = make(chan struct{})
+		exited = make(chan struct{})
+	)
+
+	c.progressEvents = events
+
+	// flush sends event and the queued events that fit in the progress channel
+	flush := func(event ProgressEvent) {
+		for {
+			select {
+			case c.progressCh <- event:
+			default:
+			}
+
+			select {
+			case event = <-events:
+			default:
+				return
+			}
+		}
+	}
+
+	go func() {
+		defer close(exited)
+
+		for {
+			select {
+			case event := <-events:
+				select {
+				case c.progressCh <- event:
+				case <-done:
+					flush(event)
+					return
+				}
+			case <-done:
+				select {
+				case event := <-events:
+					flush(event)
+				default:
+				}
+
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-exited
+		c.progressEvents = nil
+	}
+}
@@ -0,0 +1,164 @@
+package crawler
+
+import (
+	"context"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCrawler_Progress(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		progress   = make(chan ProgressEvent, 64)
+		counts     = make(map[ProgressEventType]int)
+		done       = make(chan struct{})
+		last       ProgressEvent
+	)
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, `<a href="/a">A</a><a href="/b">B</a><a href="/missing">Missing</a>`
+	})
+
+	httpClient.Request(link+"/a", func() (code int, body string) {
+		return http.StatusOK, ""
+	})
+
+	httpClient.Request(link+"/b", func() (code int, body string) {
+		return http.StatusOK, ""
+	})
+
+	go func() {
+		defer close(done)
+
+		for event := range progress {
+			counts[event.Type]++
+			last = event
+			assert.True(t, event.Elapsed >= 0)
+		}
+	}()
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(), WithProgressChannel(progress))
+	assert.Nil(t, err)
+
+	report, err := crawler.Start(ctx, link, 3)
+	assert.Nil(t, err)
+
+	close(progress)
+	<-done
+
+	assert.Equal(t, counts[ProgressFetched], len(report.VisitedURLs))
+	assert.Equal(t, counts[ProgressError], len(report.Errors))
+	assert.Equal(t, counts[ProgressLinkFound], 3)
+	assert.Equal(t, last.VisitedCount, 4)
+}
+
+func TestCrawler_ProgressWithoutReader(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		progress   = make(chan ProgressEvent, 1)
+		done       = make(chan CrawlReport)
+	)
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, `<a href="/a">A</a><a href="/b">B</a>`
+	})
+
+	httpClient.Request(link+"/a", func() (code int, body string) {
+		return http.StatusOK, ""
+	})
+
+	httpClient.Request(link+"/b", func() (code int, body string) {
+		return http.StatusOK, ""
+	})
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(), WithProgressChannel(progress))
+	assert.Nil(t, err)
+
+	go func() {
+		report, err := crawler.Start(context.Background(), link, 2)
+		assert.Nil(t, err)
+		done <- report
+	}()
+
+	select {
+	case report := <-done:
+		assert.Equal(t, len(report.VisitedURLs), 3)
+		assert.Equal(t, len(progress), 1)
+	case <-time.After(5 * time.Second):
+		t.Fatal("start blocked on a full progress channel")
+	}
+}
+
+func TestWithProgressBufferSize(t *testing.T) {
+	t.Run("queues events for a slow reader", func(t *testing.T) {
+		const bufferSize = 3
+
+		progress := make(chan ProgressEvent)
+
+		crawler, err := New(
+			WithDestinationDir(t.TempDir()),
+			WithProgressChannel(progress),
+			WithProgressBufferSize(bufferSize),
+		)
+		assert.Nil(t, err)
+
+		stop := crawler.startProgress()
+
+		// The first event is held by the forwarder until it is read
+		crawler.emitProgress(ProgressFetched, "0", 0)
+		for len(crawler.progressEvents) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		for i := 1; i < 10; i++ {
+			crawler.emitProgress(ProgressFetched, strconv.Itoa(i), 0)
+		}
+
+		for i := range bufferSize + 1 {
+			select {
+			case event := <-progress:
+				assert.Equal(t, event.URL, strconv.Itoa(i))
+			case <-time.After(5 * time.Second):
+				t.Fatalf("event %d was not delivered", i)
+			}
+		}
+
+		stop()
+
+		select {
+		case event := <-progress:
+			t.Fatalf("unexpected event %q after the queue was full", event.URL)
+		default:
+		}
+	})
+
+	t.Run("drops queued events that do not fit when stopped", func(t *testing.T) {
+		progress := make(chan ProgressEvent, 1)
+
+		crawler, err := New(WithDestinationDir(t.TempDir()), WithProgressChannel(progress))
+		assert.Nil(t, err)
+
+		stop := crawler.startProgress()
+		for i := range 10 {
+			crawler.emitProgress(ProgressFetched, strconv.Itoa(i), 0)
+		}
+
+		stop()
+		assert.Equal(t, len(progress), 1)
+	})
+
+	t.Run("rejects invalid options", func(t *testing.T) {
+		_, err := New(WithDestinationDir(t.TempDir()), WithProgressBufferSize(0))
+		assert.NotNil(t, err)
+
+		_, err = New(WithDestinationDir(t.TempDir()), WithProgressChannel(nil))
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,96 @@
+package crawler
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimit is the requests per second and burst applied to a single domain.
+type rateLimit struct {
+	rps   float64
+	burst int
+}
+
+// limiter returns a rate.Limiter for the limit. A non-positive rps is unlimited.
+func (l rateLimit) limiter() *rate.Limiter {
+	if l.rps <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+
+	return rate.NewLimiter(rate.Limit(l.rps), l.burst)
+}
+
+// DomainRateLimiter limits the rate of requests made to each domain.
+//
+// Each host gets its own token bucket, configured either by a host-specific limit
+// or by the default limit. It is safe for concurrent use.
+type DomainRateLimiter struct {
+	mu           sync.Mutex
+	defaultLimit rateLimit
+	limits       map[string]rateLimit
+	limiters     map[string]*rate.Limiter
+}
+
+// SetDefaultLimit sets the limit applied to hosts without a host-specific limit.
+// A non-positive rps disables the default limit.
+func (d *DomainRateLimiter) SetDefaultLimit(rps float64, burst int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.defaultLimit = rateLimit{rps: rps, burst: burst}
+
+	// Rebuild limiters lazily so existing hosts pick up the new default
+	for host := range d.limiters {
+		if _, ok := d.limits[host]; !ok {
+			delete(d.limiters, host)
+		}
+	}
+}
+
+// SetLimit sets the limit for a single host.
+func (d *DomainRateLimiter) SetLimit(host string, rps float64, burst int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	host = strings.ToLower(host)
+	d.limits[host] = rateLimit{rps: rps, burst: burst}
+	delete(d.limiters, host)
+}
+
+// limiter returns the limiter for host, creating it on first use.
+func (d *DomainRateLimiter) limiter(host string) *rate.Limiter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	host = strings.ToLower(host)
+	if l, ok := d.limiters[host]; ok {
+		return l
+	}
+
+	limit, ok := d.limits[host]
+	if !ok {
+		limit = d.defaultLimit
+	}
+
+	l := limit.limiter()
+	d.limiters[host] = l
+	return l
+}
+
+// Wait blocks until a request to host is allowed or ctx is done.
+func (d *DomainRateLimiter) Wait(ctx context.Context, host string) error {
+	return d.limiter(host).Wait(ctx)
+}
+
+// NewDomainRateLimiter creates a DomainRateLimiter with the given default limit.
+// A non-positive rps leaves domains without a host-specific limit unlimited.
+func NewDomainRateLimiter(rps float64, burst int) *DomainRateLimiter {
+	return &DomainRateLimiter{
+		defaultLimit: rateLimit{rps: rps, burst: burst},
+		limits:       make(map[string]rateLimit),
+		limiters:     make(map[string]*rate.Limiter),
+	}
+}
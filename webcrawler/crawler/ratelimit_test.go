@@ -0,0 +1,112 @@
+package crawler
+
+import (
+	"context"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDomainRateLimiter_Wait(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		limiter = NewDomainRateLimiter(0, 0)
+	)
+
+	limiter.SetLimit("slow.com", 20, 1)
+
+	t.Run("spaces requests to a limited host", func(t *testing.T) {
+		start := time.Now()
+		for range 3 {
+			assert.Nil(t, limiter.Wait(ctx, "slow.com"))
+		}
+
+		// The first request uses the burst, the next two wait 50ms each
+		assert.True(t, time.Since(start) >= 100*time.Millisecond)
+	})
+
+	t.Run("does not limit other hosts", func(t *testing.T) {
+		start := time.Now()
+		for range 100 {
+			assert.Nil(t, limiter.Wait(ctx, "fast.com"))
+		}
+
+		assert.True(t, time.Since(start) < 50*time.Millisecond)
+	})
+
+	t.Run("stops waiting when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		assert.NotNil(t, limiter.Wait(ctx, "slow.com"))
+	})
+}
+
+func TestCrawler_RateLimit(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		rps        = 20.0
+
+		mu         sync.Mutex
+		requestLog []time.Time
+	)
+
+	respond := func(body string) func() (int, string) {
+		return func() (int, string) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			requestLog = append(requestLog, time.Now())
+			return http.StatusOK, body
+		}
+	}
+
+	httpClient.Request(link, respond(`
+		<a href="/one">One</a>
+		<a href="/two">Two</a>
+		<a href="/three">Three</a>`))
+	httpClient.Request(link+"/one", respond(""))
+	httpClient.Request(link+"/two", respond(""))
+	httpClient.Request(link+"/three", respond(""))
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(),
+		WithDefaultRateLimit(1, 1),
+		WithDomainRateLimit("localhost.com", rps, 1),
+	)
+	assert.Nil(t, err)
+
+	report, err := crawler.Start(ctx, link, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, len(report.VisitedURLs), 4)
+	assert.Equal(t, len(requestLog), 4)
+
+	interval := time.Duration(float64(time.Second) / rps)
+	tolerance := 5 * time.Millisecond
+
+	for i := 1; i < len(requestLog); i++ {
+		assert.True(t, requestLog[i].Sub(requestLog[i-1]) >= interval-tolerance)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{name: "empty host", opt: WithDomainRateLimit("", 1, 1)},
+		{name: "zero rps", opt: WithDomainRateLimit("localhost.com", 0, 1)},
+		{name: "zero burst", opt: WithDefaultRateLimit(1, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(WithDestinationDir(t.TempDir()), tt.opt)
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PageResult describes a page fetched during a crawl.
+type PageResult struct {
+	URL           string
+	StatusCode    int
+	ContentLength int64
+	FetchedAt     time.Time
+	// Depth is the number of links followed from the starting URL to reach the page.
+	Depth int
+	// Retries is the number of times the download was retried after a transient error.
+	Retries int
+	// Metadata holds the title, description and other metadata the page declares.
+	Metadata PageMetadata
+	// Header holds the response headers of the page. It is only set when headers are saved.
+	Header http.Header
+	// TextContent is the visible text of the page. It is only set when text extraction is enabled.
+	TextContent string
+	// ContentHash is the hex encoded SHA-256 hash of the page content.
+	ContentHash string
+	// LocalFile is the path of the file the page is stored in. It is empty when the page is
+	// not stored in a local file, such as with a MemoryStorage.
+	LocalFile string
+}
+
+// CrawlError describes a page that could not be fetched.
+type CrawlError struct {
+	URL   string
+	Depth int
+	Err   error
+}
+
+// Error implements the error interface.
+func (e CrawlError) Error() string {
+	return fmt.Sprintf("%s: %v", e.URL, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e CrawlError) Unwrap() error {
+	return e.Err
+}
+
+// BrokenLink is a link whose target responded with a 4xx or 5xx status.
+type BrokenLink struct {
+	// SourceURL is the page the link was first found on. It is empty for starting URLs.
+	SourceURL  string
+	TargetURL  string
+	StatusCode int
+	Error      string
+}
+
+// IsClientError reports whether the target responded with a 4xx status, such as a missing page.
+func (b BrokenLink) IsClientError() bool {
+	return b.StatusCode >= 400 && b.StatusCode < 500
+}
+
+// IsServerError reports whether the target responded with a 5xx status.
+func (b BrokenLink) IsServerError() bool {
+	return b.StatusCode >= 500
+}
+
+// Reasons a crawl can be interrupted, reported in CrawlReport.InterruptReason.
+const (
+	// InterruptCanceled means the context passed to Start was canceled or expired.
+	InterruptCanceled = "canceled"
+	// InterruptTimeout means the crawl timeout expired.
+	InterruptTimeout = "timeout"
+	// InterruptMaxPages means the maximum number of pages was reached.
+	InterruptMaxPages = "max pages"
+	// InterruptMaxBytes means the maximum number of bytes was reached.
+	InterruptMaxBytes = "max bytes"
+)
+
+// CrawlReport summarizes a crawl.
+type CrawlReport struct {
+	// VisitedURLs holds the pages that were fetched, from the network or the cache.
+	VisitedURLs []PageResult
+	// TotalBytes is the number of bytes downloaded over the network. Pages read from
+	// the cache are not counted.
+	TotalBytes int64
+	Duration   time.Duration
+	Errors     []CrawlError
+	// BrokenLinks holds the links whose target responded with a 4xx or 5xx status.
+	BrokenLinks []BrokenLink
+	// Duplicates maps the URLs whose content duplicates a page downloaded earlier to the
+	// URL of that page. It is only populated when content deduplication is enabled.
+	Duplicates map[string]string
+	// Interrupted is true when the crawl stopped before visiting every reachable page.
+	Interrupted bool
+	// InterruptReason is why the crawl was interrupted, one of the Interrupt constants. It
+	// is empty when the crawl was not interrupted.
+	InterruptReason string
+}
+
+// URLs returns the URLs of the fetched pages.
+func (r CrawlReport) URLs() []string {
+	urls := make([]string, 0, len(r.VisitedURLs))
+
+	for _, page := range r.VisitedURLs {
+		urls = append(urls, page.URL)
+	}
+
+	return urls
+}
@@ -0,0 +1,90 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// StatusError is returned when a request completes with an unexpected status code.
+type StatusError struct {
+	StatusCode int
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request failed with status: %d", e.StatusCode)
+}
+
+// Is reports a 404 status as ErrPageNotFound.
+func (e *StatusError) Is(target error) bool {
+	return target == ErrPageNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// isRetryable reports whether a failed download may succeed when retried. Timeouts and
+// server errors are retried; client errors and cancellation are not. Request timeouts,
+// such as the client timeout, match context.DeadlineExceeded and are retried; a download
+// whose context is done is never retried by downloadWithRetry.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryDelay returns the delay before retry attempt (starting at 0): baseDelay doubled for
+// every attempt, capped at maxDelay, with ±10% jitter.
+func (c *Crawler) retryDelay(attempt int) time.Duration {
+	delay := c.retryBaseDelay << attempt
+	if delay <= 0 || delay > c.retryMaxDelay {
+		delay = c.retryMaxDelay
+	}
+
+	jitter := (rand.Float64()*0.2 - 0.1) * float64(delay)
+	return delay + time.Duration(jitter)
+}
+
+// downloadWithRetry calls download, retrying retryable errors up to the configured
+// number of attempts. Every retry waits for the per-host rate limit and politeness delay
+// like the first request does. It returns the number of retries made.
+func (c *Crawler) downloadWithRetry(ctx context.Context, uri string, key string, cached CacheEntry) (*bytes.Buffer, int, error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if parsed, err := url.Parse(uri); err == nil {
+				if err := c.waitHost(ctx, parsed.Host); err != nil {
+					return nil, attempt, err
+				}
+			}
+		}
+
+		buffer, err := c.download(ctx, uri, key, cached)
+		if err == nil {
+			return buffer, attempt, nil
+		}
+
+		if attempt+1 >= c.retryAttempts || ctx.Err() != nil || !isRetryable(err) {
+			return nil, attempt, err
+		}
+
+		timer := time.NewTimer(c.retryDelay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// timeoutError is a net.Error that reports a timeout.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "server error", err: &StatusError{StatusCode: http.StatusBadGateway}, want: true},
+		{name: "wrapped server error", err: fmt.Errorf("download: %w", &StatusError{StatusCode: 500}), want: true},
+		{name: "not found", err: &StatusError{StatusCode: http.StatusNotFound}, want: false},
+		{name: "timeout", err: fmt.Errorf("do request: %w", timeoutError{}), want: true},
+		{name: "canceled", err: context.Canceled, want: false},
+		{name: "deadline exceeded", err: fmt.Errorf("do request: %w", context.DeadlineExceeded), want: true},
+		{name: "other", err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, isRetryable(tt.err), tt.want)
+		})
+	}
+}
+
+func TestCrawler_Retry(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+	)
+
+	t.Run("retries server errors", func(t *testing.T) {
+		attempts := 0
+		httpClient.Request(link, func() (code int, body string) {
+			attempts++
+			if attempts <= 2 {
+				return http.StatusInternalServerError, ""
+			}
+			return http.StatusOK, "<p>ok</p>"
+		})
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithRetry(3, time.Millisecond, 5*time.Millisecond))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, attempts, 3)
+		assert.Equal(t, len(report.VisitedURLs), 1)
+		assert.Equal(t, report.VisitedURLs[0].Retries, 2)
+	})
+
+	t.Run("waits for the politeness delay between attempts", func(t *testing.T) {
+		const delay = 100 * time.Millisecond
+
+		var requests []time.Time
+		httpClient.Request(link, func() (code int, body string) {
+			requests = append(requests, time.Now())
+			return http.StatusInternalServerError, ""
+		})
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(),
+			WithRetry(3, time.Millisecond, time.Millisecond),
+			WithPolitenessDelay(delay),
+		)
+		assert.Nil(t, err)
+
+		_, err = crawler.Start(ctx, link, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, len(requests), 3)
+
+		for i := 1; i < len(requests); i++ {
+			if gap := requests[i].Sub(requests[i-1]); gap < delay/2 {
+				t.Errorf("attempt %d started %s after the previous one, want about %s", i, gap, delay)
+			}
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		attempts := 0
+		httpClient.Request(link, func() (code int, body string) {
+			attempts++
+			return http.StatusServiceUnavailable, ""
+		})
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithRetry(2, time.Millisecond, time.Millisecond))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, attempts, 2)
+		assert.Equal(t, len(report.Errors), 1)
+	})
+
+	t.Run("does not retry not found", func(t *testing.T) {
+		attempts := 0
+		httpClient.Request(link, func() (code int, body string) {
+			attempts++
+			return http.StatusNotFound, ""
+		})
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithRetry(3, time.Millisecond, time.Millisecond))
+		assert.Nil(t, err)
+
+		_, err = crawler.Fetch(ctx, link)
+		assert.ErrorIs(t, err, ErrPageNotFound)
+		assert.Equal(t, attempts, 1)
+	})
+
+	t.Run("delay grows up to the max delay", func(t *testing.T) {
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithRetry(5, 10*time.Millisecond, 30*time.Millisecond))
+		assert.Nil(t, err)
+
+		for attempt, want := range []time.Duration{10, 20, 30, 30} {
+			want *= time.Millisecond
+			delay := crawler.retryDelay(attempt)
+			assert.True(t, delay >= want-want/10 && delay <= want+want/10)
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		for _, opt := range []Option{
+			WithRetry(0, time.Millisecond, time.Millisecond),
+			WithRetry(1, 0, time.Millisecond),
+			WithRetry(1, time.Second, time.Millisecond),
+		} {
+			_, err := NewCrawler(httpClient, t.TempDir(), opt)
+			assert.NotNil(t, err)
+		}
+	})
+}
+
+func TestCrawler_RetryTimeout(t *testing.T) {
+	var attempts atomic.Int32
+
+	// The first response is slower than the request timeout, later ones are immediate
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+		}
+
+		_, _ = w.Write([]byte("<p>ok</p>"))
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name string
+		opt  Option
+	}{
+		{name: "client timeout", opt: WithTimeout(100 * time.Millisecond)},
+		{
+			name: "response header timeout",
+			opt:  WithTransportConfig(TransportConfig{ResponseHeaderTimeout: 100 * time.Millisecond}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts.Store(0)
+
+			crawler, err := New(
+				WithDestinationDir(t.TempDir()),
+				WithRetry(3, time.Millisecond, 5*time.Millisecond),
+				tt.opt,
+			)
+			assert.Nil(t, err)
+
+			report, err := crawler.Start(context.Background(), server.URL, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, len(report.Errors), 0)
+			assert.Equal(t, len(report.VisitedURLs), 1)
+			assert.Equal(t, report.VisitedURLs[0].Retries, 1)
+			assert.Equal(t, attempts.Load(), int32(2))
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package crawler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRobotsCacheExpiry is how long a host's robots.txt is cached when
+// RobotsConfig.CacheExpiry is not set.
+const DefaultRobotsCacheExpiry = 24 * time.Hour
+
+// ErrDisallowedByRobots is returned when a URL is disallowed by the host's robots.txt.
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+// RobotsConfig controls how the crawler handles robots.txt files.
+type RobotsConfig struct {
+	// Respect enables robots.txt checks before fetching a page.
+	Respect bool
+	// UserAgent is the name matched against User-agent lines. Only the "*" group
+	// is used when it is empty.
+	UserAgent string
+	// CacheExpiry is how long a fetched robots.txt is reused for a host.
+	CacheExpiry time.Duration
+}
+
+// robotsRule is a single Allow or Disallow line.
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// robotsGroup holds the rules that apply to a set of user agents.
+type robotsGroup struct {
+	agents []string
+	rules  []robotsRule
+}
+
+// robotsData is a parsed robots.txt file.
+type robotsData struct {
+	groups []*robotsGroup
+}
+
+// robotsEntry is a cached robots.txt for a single host. mu is held while the robots.txt
+// is fetched so that it is fetched once without blocking checks for other hosts.
+type robotsEntry struct {
+	mu        sync.Mutex
+	data      *robotsData
+	fetchedAt time.Time
+}
+
+// parseRobots parses a robots.txt file. Unknown directives are ignored.
+func parseRobots(r io.Reader) (*robotsData, error) {
+	var (
+		data    = &robotsData{}
+		current *robotsGroup
+		inRules bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive User-agent lines share the rules that follow them
+			if current == nil || inRules {
+				current = &robotsGroup{}
+				data.groups = append(data.groups, current)
+				inRules = false
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+
+		case "allow", "disallow":
+			if current == nil {
+				continue
+			}
+			inRules = true
+
+			// An empty Disallow allows everything
+			if value == "" {
+				continue
+			}
+
+			current.rules = append(current.rules, robotsRule{
+				allow:   key == "allow",
+				length:  len(value),
+				pattern: robotsPattern(value),
+			})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("scan robots.txt: %w", err)
+	}
+
+	return data, nil
+}
+
+// robotsPattern compiles a robots.txt path into a regular expression,
+// supporting the "*" wildcard and the "$" end anchor.
+func robotsPattern(path string) *regexp.Regexp {
+	anchored := strings.HasSuffix(path, "$")
+	path = strings.TrimSuffix(path, "$")
+
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(path), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+
+	return regexp.MustCompile(expr)
+}
+
+// group returns the group that applies to userAgent, falling back to the "*" group.
+func (d *robotsData) group(userAgent string) *robotsGroup {
+	userAgent = strings.ToLower(userAgent)
+
+	var fallback *robotsGroup
+	for _, g := range d.groups {
+		for _, agent := range g.agents {
+			if agent == "*" {
+				if fallback == nil {
+					fallback = g
+				}
+				continue
+			}
+
+			if userAgent != "" && strings.Contains(userAgent, agent) {
+				return g
+			}
+		}
+	}
+
+	return fallback
+}
+
+// allowed reports whether userAgent may fetch path. The longest matching rule wins
+// and Allow wins over Disallow when both match with the same length.
+func (d *robotsData) allowed(userAgent, path string) bool {
+	g := d.group(userAgent)
+	if g == nil {
+		return true
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	var (
+		allow   = true
+		longest = -1
+	)
+
+	for _, rule := range g.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+
+		if rule.length > longest || (rule.length == longest && rule.allow) {
+			allow = rule.allow
+			longest = rule.length
+		}
+	}
+
+	return allow
+}
+
+// fetchRobots downloads and parses the robots.txt file for the host of uri.
+// A missing or unreadable robots.txt allows everything.
+func (c *Crawler) fetchRobots(ctx context.Context, uri *url.URL) (*robotsData, error) {
+	robotsURL := url.URL{Scheme: uri.Scheme, Host: uri.Host, Path: "/robots.txt"}
+
+	req, err := c.newRequest(ctx, robotsURL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return &robotsData{}, nil
+	}
+
+	body, err := decodeBody(resp.Body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return nil, fmt.Errorf("decode robots.txt: %w", err)
+	}
+
+	return parseRobots(body)
+}
+
+// robotsAllowed reports whether the robots.txt of the host of uri allows fetching it.
+// The robots.txt is fetched once per host and cached for the configured expiry.
+func (c *Crawler) robotsAllowed(ctx context.Context, uri *url.URL) (bool, error) {
+	c.robotsMu.Lock()
+	entry, ok := c.robotsCache[uri.Host]
+	if !ok {
+		entry = &robotsEntry{}
+		c.robotsCache[uri.Host] = entry
+	}
+	c.robotsMu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.data == nil || time.Since(entry.fetchedAt) > c.robots.CacheExpiry {
+		data, err := c.fetchRobots(ctx, uri)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, err
+			}
+
+			c.logger.Warn("failed to fetch robots.txt, allowing all", logAttrs(ctx, uri.String(), "host", uri.Host, "error", err)...)
+			data = &robotsData{}
+		}
+
+		entry.data = data
+		entry.fetchedAt = time.Now()
+	}
+
+	return entry.data.allowed(c.robots.UserAgent, uri.EscapedPath()), nil
+}
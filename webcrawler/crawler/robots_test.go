@@ -0,0 +1,189 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRobots(t *testing.T) {
+	data, err := parseRobots(strings.NewReader(`
+# Block the admin area for everyone
+User-agent: *
+Disallow: /admin/
+Allow: /admin/public
+Disallow: /*.pdf$
+
+User-agent: kitchen-crawler
+User-agent: other-bot
+Disallow: /private
+Disallow:
+`))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name      string
+		userAgent string
+		path      string
+		want      bool
+	}{
+		{name: "allowed path", userAgent: "", path: "/about", want: true},
+		{name: "disallowed prefix", userAgent: "", path: "/admin/users", want: false},
+		{name: "longer allow wins", userAgent: "", path: "/admin/public/page", want: true},
+		{name: "wildcard with anchor", userAgent: "", path: "/files/report.pdf", want: false},
+		{name: "anchor does not match suffix", userAgent: "", path: "/files/report.pdf.html", want: true},
+		{name: "root path", userAgent: "", path: "", want: true},
+		{name: "specific agent group", userAgent: "Kitchen-Crawler/1.0", path: "/private/data", want: false},
+		{name: "specific agent ignores wildcard group", userAgent: "kitchen-crawler", path: "/admin/users", want: true},
+		{name: "unknown agent uses wildcard group", userAgent: "some-bot", path: "/admin/users", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, data.allowed(tt.userAgent, tt.path), tt.want)
+		})
+	}
+}
+
+func TestCrawler_Robots(t *testing.T) {
+	var (
+		link         = "http://localhost.com"
+		httpClient   = testutil.NewTestHttpClient()
+		ctx          = context.Background()
+		robotsHits   atomic.Int32
+		adminFetches atomic.Int32
+	)
+
+	httpClient.Request(link+"/robots.txt", func() (code int, body string) {
+		robotsHits.Add(1)
+		return http.StatusOK, "User-agent: *\nDisallow: /admin/\n"
+	})
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, `
+			<a href="/about">About</a>
+			<a href="/admin/users">Users</a>
+			<a href="/admin/settings">Settings</a>`
+	})
+
+	httpClient.Request(link+"/about", func() (code int, body string) {
+		return http.StatusOK, `<a href="/admin/users">Users</a>`
+	})
+
+	for _, path := range []string{"/admin/users", "/admin/settings"} {
+		httpClient.Request(link+path, func() (code int, body string) {
+			adminFetches.Add(1)
+			return http.StatusOK, ""
+		})
+	}
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(), WithRobots(RobotsConfig{Respect: true}))
+	assert.Nil(t, err)
+
+	_, err = crawler.Start(ctx, link, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, adminFetches.Load(), int32(0))
+	assert.Equal(t, robotsHits.Load(), int32(1))
+
+	_, err = crawler.Fetch(ctx, link+"/admin/users")
+	assert.ErrorIs(t, err, ErrDisallowedByRobots)
+}
+
+func TestCrawler_RobotsCompressed(t *testing.T) {
+	var (
+		gzipped      bytes.Buffer
+		adminFetches atomic.Int32
+	)
+
+	gz := gzip.NewWriter(&gzipped)
+	_, err := gz.Write([]byte("User-agent: *\nDisallow: /admin/\n"))
+	assert.Nil(t, err)
+	assert.Nil(t, gz.Close())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(gzipped.Bytes())
+	})
+	mux.HandleFunc("/admin/", func(w http.ResponseWriter, r *http.Request) {
+		adminFetches.Add(1)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<a href="/about">About</a><a href="/admin/secret">Secret</a>`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	crawler, err := New(
+		WithDestinationDir(t.TempDir()),
+		WithRobots(RobotsConfig{Respect: true}),
+		WithRequestCompression(true),
+	)
+	assert.Nil(t, err)
+
+	report, err := crawler.Start(context.Background(), server.URL, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, len(report.VisitedURLs), 2)
+	assert.Equal(t, adminFetches.Load(), int32(0))
+}
+
+func TestCrawler_RobotsSlowHost(t *testing.T) {
+	var (
+		release   = make(chan struct{})
+		requested = make(chan struct{})
+	)
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-release
+		_, _ = w.Write([]byte("User-agent: *\nDisallow:\n"))
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(release) })
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /admin/\n"))
+	}))
+	t.Cleanup(fast.Close)
+
+	crawler, err := New(WithDestinationDir(t.TempDir()), WithRobots(RobotsConfig{Respect: true}))
+	assert.Nil(t, err)
+
+	ctx := context.Background()
+
+	slowURL, err := url.Parse(slow.URL + "/page")
+	assert.Nil(t, err)
+
+	go func() {
+		_, _ = crawler.robotsAllowed(ctx, slowURL)
+	}()
+
+	<-requested
+
+	fastURL, err := url.Parse(fast.URL + "/admin/users")
+	assert.Nil(t, err)
+
+	done := make(chan bool)
+	go func() {
+		allowed, err := crawler.robotsAllowed(ctx, fastURL)
+		assert.Nil(t, err)
+		done <- allowed
+	}()
+
+	select {
+	case allowed := <-done:
+		assert.False(t, allowed)
+	case <-time.After(time.Second):
+		t.Fatal("robots.txt check blocked by a slow host")
+	}
+}
@@ -0,0 +1,335 @@
+package crawler
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sitemapNamespace is the XML namespace of the sitemap protocol.
+const sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// maxSitemapURLs is the maximum number of URLs a sitemap may list.
+const maxSitemapURLs = 50000
+
+// sitemapChangeFreqs are the values allowed in a <changefreq> element.
+var sitemapChangeFreqs = []string{"always", "hourly", "daily", "weekly", "monthly", "yearly", "never"}
+
+// sitemapPaths are the well-known sitemap locations checked when sitemap fetching is enabled.
+var sitemapPaths = []string{"/sitemap.xml", "/sitemap_index.xml"}
+
+// sitemapDocument matches both <urlset> and <sitemapindex> documents.
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+// sitemapLoc is a <url> or <sitemap> entry.
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// parseSitemap parses a sitemap and reports whether it is a sitemap index.
+// Gzipped sitemaps are decompressed transparently.
+func parseSitemap(r io.Reader) (locs []string, index bool, err error) {
+	reader := bufio.NewReader(r)
+
+	// Detect gzip by its magic number rather than by file extension or headers
+	if magic, _ := reader.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, false, fmt.Errorf("gzip reader: %w", err)
+		}
+
+		defer func(gz *gzip.Reader) {
+			_ = gz.Close()
+		}(gz)
+
+		r = gz
+	} else {
+		r = reader
+	}
+
+	var doc sitemapDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, false, fmt.Errorf("decode sitemap: %w", err)
+	}
+
+	entries := doc.URLs
+	if doc.XMLName.Local == "sitemapindex" {
+		entries, index = doc.Sitemaps, true
+	}
+
+	for _, entry := range entries {
+		if loc := strings.TrimSpace(entry.Loc); loc != "" {
+			locs = append(locs, loc)
+		}
+	}
+
+	return locs, index, nil
+}
+
+// ParseSitemap returns the <loc> values of a sitemap. For a sitemap index, the locations
+// of the child sitemaps are returned. Gzipped sitemaps are decompressed transparently.
+func ParseSitemap(r io.Reader) ([]string, error) {
+	locs, _, err := parseSitemap(r)
+	return locs, err
+}
+
+// downloadSitemap downloads and parses the sitemap at rawURL. Like page requests, it waits
+// while the crawler is paused and for the rate limit and politeness delay of the host.
+func (c *Crawler) downloadSitemap(ctx context.Context, rawURL string) ([]string, bool, error) {
+	req, err := c.newRequest(ctx, rawURL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := c.waitResumed(ctx); err != nil {
+		return nil, false, err
+	}
+
+	if err := c.waitHost(ctx, req.URL.Host); err != nil {
+		return nil, false, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("do request: %w", err)
+	}
+
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	body, err := decodeBody(resp.Body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return nil, false, fmt.Errorf("decode sitemap: %w", err)
+	}
+
+	return parseSitemap(body)
+}
+
+// sitemapSeeds fetches the well-known sitemaps of the host of startURL and returns the
+// locations that are children of startURL. Sitemap indexes are followed one level deep.
+func (c *Crawler) sitemapSeeds(ctx context.Context, startURL *url.URL) []string {
+	var (
+		seeds []string
+		seen  = make(map[string]struct{})
+	)
+
+	add := func(locs []string) {
+		for _, loc := range locs {
+			parsed, err := url.Parse(loc)
+			if err != nil || !inScope(startURL, parsed) {
+				continue
+			}
+
+			if _, ok := seen[loc]; ok {
+				continue
+			}
+
+			seen[loc] = struct{}{}
+			seeds = append(seeds, loc)
+		}
+	}
+
+	for _, path := range sitemapPaths {
+		sitemapURL := url.URL{Scheme: startURL.Scheme, Host: startURL.Host, Path: path}
+
+		sitemapCtx := withVisit(ctx, 0, time.Now())
+
+		locs, index, err := c.downloadSitemap(sitemapCtx, sitemapURL.String())
+		if err != nil {
+			if !errors.Is(err, ErrPageNotFound) {
+				c.logger.Warn("failed to fetch sitemap", logAttrs(sitemapCtx, sitemapURL.String(), "error", err)...)
+			}
+			continue
+		}
+
+		if !index {
+			add(locs)
+			continue
+		}
+
+		for _, child := range locs {
+			childCtx := withVisit(ctx, 0, time.Now())
+
+			childLocs, childIndex, err := c.downloadSitemap(childCtx, child)
+			if err != nil {
+				c.logger.Warn("failed to fetch sitemap", logAttrs(childCtx, child, "error", err)...)
+				continue
+			}
+
+			// Nested indexes are not followed
+			if !childIndex {
+				add(childLocs)
+			}
+		}
+	}
+
+	return seeds
+}
+
+// withinBaseURL reports whether rawURL has the same scheme and host as base and a path
+// within the path of base, ending at a segment boundary.
+func withinBaseURL(base *url.URL, rawURL string) bool {
+	uri, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	if !strings.EqualFold(uri.Scheme, base.Scheme) || !strings.EqualFold(uri.Host, base.Host) {
+		return false
+	}
+
+	basePath := strings.TrimSuffix(base.Path, "/")
+	return uri.Path == basePath || strings.HasPrefix(uri.Path, basePath+"/")
+}
+
+// sitemapURLSet is the <urlset> written by GenerateSitemap.
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+// sitemapURL is a <url> entry written by GenerateSitemap.
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+// sitemapConfig holds the defaults applied to every <url> entry of a generated sitemap.
+type sitemapConfig struct {
+	changeFreq string
+	priority   string
+}
+
+// SitemapOption configures the sitemap written by GenerateSitemap.
+type SitemapOption func(cfg *sitemapConfig) error
+
+// WithSitemapChangeFreq sets the <changefreq> of every URL, one of "always", "hourly",
+// "daily", "weekly", "monthly", "yearly" or "never".
+func WithSitemapChangeFreq(freq string) SitemapOption {
+	return func(cfg *sitemapConfig) error {
+		if !slices.Contains(sitemapChangeFreqs, freq) {
+			return fmt.Errorf("invalid change frequency %q", freq)
+		}
+
+		cfg.changeFreq = freq
+		return nil
+	}
+}
+
+// WithSitemapPriority sets the <priority> of every URL, between 0.0 and 1.0.
+func WithSitemapPriority(priority float64) SitemapOption {
+	return func(cfg *sitemapConfig) error {
+		if priority < 0 || priority > 1 {
+			return fmt.Errorf("priority must be between 0 and 1, got %v", priority)
+		}
+
+		cfg.priority = strconv.FormatFloat(priority, 'f', 1, 64)
+		return nil
+	}
+}
+
+// GenerateSitemap writes an XML sitemap listing the pages fetched during the crawl
+// described by report to w. The URLs are sorted and each is listed once.
+//
+// The <lastmod> of a page is taken from its Last-Modified header when headers were saved,
+// and from the time it was fetched otherwise. <changefreq> and <priority> are only written
+// when configured with options.
+//
+// An error is returned when a URL in the report is not under baseURL, that is when its
+// scheme or host differ or its path is not within the path of baseURL, or when the report
+// holds more URLs than a sitemap may list.
+func GenerateSitemap(report CrawlReport, baseURL string, w io.Writer, opts ...SitemapOption) error {
+	var cfg sitemapConfig
+
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return fmt.Errorf("apply sitemap option: %w", err)
+		}
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("parse base url: %w", err)
+	}
+
+	var (
+		urlSet = sitemapURLSet{Xmlns: sitemapNamespace}
+		seen   = make(map[string]struct{}, len(report.VisitedURLs))
+	)
+
+	for _, page := range report.VisitedURLs {
+		if !withinBaseURL(base, page.URL) {
+			return fmt.Errorf("url %q is not under base url %q", page.URL, baseURL)
+		}
+
+		if _, ok := seen[page.URL]; ok {
+			continue
+		}
+
+		seen[page.URL] = struct{}{}
+
+		urlSet.URLs = append(urlSet.URLs, sitemapURL{
+			Loc:        page.URL,
+			LastMod:    lastModified(page),
+			ChangeFreq: cfg.changeFreq,
+			Priority:   cfg.priority,
+		})
+	}
+
+	if len(urlSet.URLs) > maxSitemapURLs {
+		return fmt.Errorf("sitemap must not list more than %d urls, got %d", maxSitemapURLs, len(urlSet.URLs))
+	}
+
+	slices.SortFunc(urlSet.URLs, func(a, b sitemapURL) int {
+		return strings.Compare(a.Loc, b.Loc)
+	})
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("write sitemap: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(urlSet); err != nil {
+		return fmt.Errorf("encode sitemap: %w", err)
+	}
+
+	return nil
+}
+
+// lastModified returns the <lastmod> of page in the W3C datetime format, or an empty
+// string when it is unknown.
+func lastModified(page PageResult) string {
+	if modified, err := http.ParseTime(page.Header.Get("Last-Modified")); err == nil {
+		return modified.UTC().Format(time.RFC3339)
+	}
+
+	if page.FetchedAt.IsZero() {
+		return ""
+	}
+
+	return page.FetchedAt.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,329 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSitemap = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url>
+		<loc>http://localhost.com/</loc>
+		<lastmod>2024-01-01</lastmod>
+	</url>
+	<url>
+		<loc> http://localhost.com/orphan </loc>
+	</url>
+	<url>
+		<loc>http://localhost.com/blog/post</loc>
+	</url>
+</urlset>`
+
+func TestParseSitemap(t *testing.T) {
+	want := []string{
+		"http://localhost.com/",
+		"http://localhost.com/orphan",
+		"http://localhost.com/blog/post",
+	}
+
+	t.Run("parses a url set", func(t *testing.T) {
+		locs, err := ParseSitemap(strings.NewReader(testSitemap))
+		assert.Nil(t, err)
+		assert.Equal(t, locs, want)
+	})
+
+	t.Run("parses a gzipped url set", func(t *testing.T) {
+		var buffer bytes.Buffer
+		gz := gzip.NewWriter(&buffer)
+		_, err := gz.Write([]byte(testSitemap))
+		assert.Nil(t, err)
+		assert.Nil(t, gz.Close())
+
+		locs, err := ParseSitemap(&buffer)
+		assert.Nil(t, err)
+		assert.Equal(t, locs, want)
+	})
+
+	t.Run("parses a sitemap index", func(t *testing.T) {
+		locs, index, err := parseSitemap(strings.NewReader(`
+			<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+				<sitemap><loc>http://localhost.com/sitemap-pages.xml</loc></sitemap>
+			</sitemapindex>`))
+		assert.Nil(t, err)
+		assert.True(t, index)
+		assert.Equal(t, locs, []string{"http://localhost.com/sitemap-pages.xml"})
+	})
+
+	t.Run("invalid xml", func(t *testing.T) {
+		locs, err := ParseSitemap(strings.NewReader("not xml"))
+		assert.NotNil(t, err)
+		assert.Nil(t, locs)
+	})
+}
+
+func TestGenerateSitemap(t *testing.T) {
+	var (
+		baseURL   = "http://localhost.com"
+		fetchedAt = time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+		report    = CrawlReport{
+			VisitedURLs: []PageResult{
+				{URL: baseURL + "/blog", StatusCode: http.StatusOK, FetchedAt: fetchedAt},
+				{URL: baseURL, StatusCode: http.StatusOK, FetchedAt: fetchedAt},
+				{
+					URL:        baseURL + "/about",
+					StatusCode: http.StatusOK,
+					FetchedAt:  fetchedAt,
+					Header:     http.Header{"Last-Modified": {"Mon, 01 Jan 2024 08:00:00 GMT"}},
+				},
+				{URL: baseURL + "/blog", StatusCode: http.StatusOK},
+			},
+		}
+	)
+
+	// document mirrors the sitemap protocol to decode the generated sitemap
+	type document struct {
+		XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+		URLs    []struct {
+			Loc        string `xml:"loc"`
+			LastMod    string `xml:"lastmod"`
+			ChangeFreq string `xml:"changefreq"`
+			Priority   string `xml:"priority"`
+		} `xml:"url"`
+	}
+
+	t.Run("writes a url set", func(t *testing.T) {
+		var buffer bytes.Buffer
+		assert.Nil(t, GenerateSitemap(report, baseURL, &buffer))
+		assert.True(t, strings.HasPrefix(buffer.String(), xml.Header))
+
+		var doc document
+		assert.Nil(t, xml.Unmarshal(buffer.Bytes(), &doc))
+		assert.Equal(t, len(doc.URLs), 3)
+
+		var (
+			locs     []string
+			lastMods []string
+		)
+
+		for _, entry := range doc.URLs {
+			locs = append(locs, entry.Loc)
+			lastMods = append(lastMods, entry.LastMod)
+			assert.Equal(t, entry.ChangeFreq, "")
+			assert.Equal(t, entry.Priority, "")
+		}
+
+		assert.Equal(t, locs, []string{baseURL, baseURL + "/about", baseURL + "/blog"})
+		assert.Equal(t, lastMods, []string{"2024-05-01T12:30:00Z", "2024-01-01T08:00:00Z", "2024-05-01T12:30:00Z"})
+	})
+
+	t.Run("can be parsed as a sitemap", func(t *testing.T) {
+		var buffer bytes.Buffer
+		assert.Nil(t, GenerateSitemap(report, baseURL, &buffer))
+
+		locs, err := ParseSitemap(&buffer)
+		assert.Nil(t, err)
+		assert.Equal(t, locs, []string{baseURL, baseURL + "/about", baseURL + "/blog"})
+	})
+
+	t.Run("writes the configured defaults", func(t *testing.T) {
+		var buffer bytes.Buffer
+		assert.Nil(t, GenerateSitemap(report, baseURL, &buffer,
+			WithSitemapChangeFreq("weekly"), WithSitemapPriority(0.5)))
+
+		var doc document
+		assert.Nil(t, xml.Unmarshal(buffer.Bytes(), &doc))
+
+		for _, entry := range doc.URLs {
+			assert.Equal(t, entry.ChangeFreq, "weekly")
+			assert.Equal(t, entry.Priority, "0.5")
+		}
+	})
+
+	t.Run("rejects urls outside the base url", func(t *testing.T) {
+		var buffer bytes.Buffer
+
+		err := GenerateSitemap(report, "http://example.com", &buffer)
+		assert.NotNil(t, err)
+		assert.Equal(t, buffer.Len(), 0)
+	})
+
+	t.Run("matches the base url by host and path segments", func(t *testing.T) {
+		tests := []struct {
+			baseURL string
+			url     string
+			want    bool
+		}{
+			{baseURL: baseURL, url: baseURL + "/blog", want: true},
+			{baseURL: baseURL + "/", url: baseURL, want: true},
+			{baseURL: baseURL + "/blog", url: baseURL + "/blog/post", want: true},
+			{baseURL: baseURL + "/blog/", url: baseURL + "/blog", want: true},
+			{baseURL: baseURL, url: "http://localhost.com.evil.org/blog", want: false},
+			{baseURL: baseURL, url: "http://localhost.com:8080/blog", want: false},
+			{baseURL: baseURL, url: "https://localhost.com/blog", want: false},
+			{baseURL: baseURL + "/blog", url: baseURL + "/blogger", want: false},
+		}
+
+		for _, tt := range tests {
+			report := CrawlReport{VisitedURLs: []PageResult{{URL: tt.url, StatusCode: http.StatusOK}}}
+
+			var buffer bytes.Buffer
+			err := GenerateSitemap(report, tt.baseURL, &buffer)
+			assert.Equal(t, err == nil, tt.want)
+		}
+	})
+
+	t.Run("rejects invalid options", func(t *testing.T) {
+		tests := []struct {
+			name string
+			opt  SitemapOption
+		}{
+			{name: "change frequency", opt: WithSitemapChangeFreq("sometimes")},
+			{name: "priority", opt: WithSitemapPriority(1.5)},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var buffer bytes.Buffer
+				assert.NotNil(t, GenerateSitemap(report, baseURL, &buffer, tt.opt))
+			})
+		}
+	})
+}
+
+func TestCrawler_FetchSitemap(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+	)
+
+	httpClient.Request(link, func() (code int, body string) {
+		return http.StatusOK, `<a href="/about">About</a>`
+	})
+
+	httpClient.Request(link+"/about", func() (code int, body string) {
+		return http.StatusOK, ""
+	})
+
+	httpClient.Request(link+"/orphan", func() (code int, body string) {
+		return http.StatusOK, ""
+	})
+
+	httpClient.Request(link+"/sitemap_index.xml", func() (code int, body string) {
+		return http.StatusOK, `
+			<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+				<sitemap><loc>http://localhost.com/sitemap-pages.xml</loc></sitemap>
+			</sitemapindex>`
+	})
+
+	httpClient.Request(link+"/sitemap-pages.xml", func() (code int, body string) {
+		return http.StatusOK, `
+			<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+				<url><loc>http://localhost.com/orphan</loc></url>
+				<url><loc>http://another.domain/page</loc></url>
+			</urlset>`
+	})
+
+	crawler, err := NewCrawler(httpClient, t.TempDir(), WithFetchSitemap(true))
+	assert.Nil(t, err)
+
+	report, err := crawler.Start(ctx, link, 2)
+	assert.Nil(t, err)
+
+	links := report.URLs()
+	slices.Sort(links)
+	assert.Equal(t, links, []string{link, link + "/about", link + "/orphan"})
+}
+
+func TestCrawler_FetchSitemapCompressed(t *testing.T) {
+	var deflated bytes.Buffer
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "deflate")
+		_, _ = w.Write(deflated.Bytes())
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" && r.URL.Path != "/orphan" {
+			http.NotFound(w, r)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	writer, err := flate.NewWriter(&deflated, flate.DefaultCompression)
+	assert.Nil(t, err)
+
+	_, err = writer.Write([]byte(`
+		<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+			<url><loc>` + server.URL + `/orphan</loc></url>
+		</urlset>`))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+
+	crawler, err := New(
+		WithDestinationDir(t.TempDir()),
+		WithFetchSitemap(true),
+		WithRequestCompression(true),
+	)
+	assert.Nil(t, err)
+
+	report, err := crawler.Start(context.Background(), server.URL, 2)
+	assert.Nil(t, err)
+
+	links := report.URLs()
+	slices.Sort(links)
+	assert.Equal(t, links, []string{server.URL, server.URL + "/orphan"})
+}
+
+func TestCrawler_FetchSitemapFilters(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+	)
+
+	for _, path := range []string{"", "/orphan", "/cdn/big"} {
+		httpClient.Request(link+path, func() (code int, body string) {
+			return http.StatusOK, ""
+		})
+	}
+
+	httpClient.Request(link+"/sitemap.xml", func() (code int, body string) {
+		return http.StatusOK, `
+			<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+				<url><loc>http://localhost.com/orphan</loc></url>
+				<url><loc>http://localhost.com/cdn/big</loc></url>
+			</urlset>`
+	})
+
+	for _, traversal := range []TraversalStrategy{TraversalDFS, TraversalBFS} {
+		t.Run(string(traversal), func(t *testing.T) {
+			crawler, err := NewCrawler(httpClient, t.TempDir(),
+				WithFetchSitemap(true),
+				WithExcludePattern("/cdn/"),
+				WithTraversal(traversal),
+			)
+			assert.Nil(t, err)
+
+			report, err := crawler.Start(ctx, link, 2)
+			assert.Nil(t, err)
+
+			links := report.URLs()
+			slices.Sort(links)
+			assert.Equal(t, links, []string{link, link + "/orphan"})
+		})
+	}
+}
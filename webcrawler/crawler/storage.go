@@ -0,0 +1,300 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Storage stores fetched pages by key. Read returns an error matching fs.ErrNotExist
+// when the key does not exist.
+type Storage interface {
+	Write(key string, r io.Reader) error
+	Read(key string) (io.ReadCloser, error)
+	Exists(key string) (bool, error)
+	Delete(key string) error
+}
+
+// clearer is implemented by storages that can delete every key at once.
+type clearer interface {
+	Clear() error
+}
+
+// locator is implemented by storages that keep each key in a local file.
+type locator interface {
+	path(key string) string
+}
+
+// storageIndexFile is the file in which FileStorage records the keys it has written, one
+// JSON-encoded key per line.
+const storageIndexFile = "storage_index.jsonl"
+
+// FileStorage is a Storage that saves each key as a file in a directory. The keys it
+// writes are recorded in an index file in the directory, so that Clear only deletes the
+// files written by a FileStorage and leaves any other file in the directory alone.
+type FileStorage struct {
+	dir string
+
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+// path returns the file path of key. Slashes in key separate directories.
+func (s *FileStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
+
+// Write saves the contents of r to the file for key, replacing any existing file. The
+// directories of the file are created as needed. The file is written with writeFileAtomic.
+func (s *FileStorage) Write(key string, r io.Reader) error {
+	if key == storageIndexFile {
+		return fmt.Errorf("write %s: key is reserved for the storage index", key)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path(key)), os.ModePerm); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+
+	if err := writeFileAtomic(s.path(key), r); err != nil {
+		return err
+	}
+
+	return s.index(key)
+}
+
+// index records key in the index file unless it has been recorded already.
+func (s *FileStorage) index(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[key]; ok {
+		return nil
+	}
+
+	line, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("marshal key: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path(storageIndexFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open index: %w", err)
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write index: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close index: %w", err)
+	}
+
+	s.keys[key] = struct{}{}
+	return nil
+}
+
+// loadIndex reads the keys recorded in the index file. A missing index is not an error.
+func (s *FileStorage) loadIndex() error {
+	data, err := os.ReadFile(s.path(storageIndexFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("read index: %w", err)
+	}
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		var key string
+		if err := json.Unmarshal(line, &key); err != nil {
+			return fmt.Errorf("unmarshal index: %w", err)
+		}
+
+		s.keys[key] = struct{}{}
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes the contents of r to a temporary file in the directory of path
+// and renames it to path once complete, so a failed or interrupted write never leaves a
+// partial file behind. The file is readable by everyone, like the storage index.
+func writeFileAtomic(path string, r io.Reader) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+
+	defer func(name string) {
+		_ = os.Remove(name)
+	}(file.Name())
+
+	if _, err := io.Copy(file, r); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	// Temporary files are only readable by their owner
+	if err := file.Chmod(0o644); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("chmod file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close file: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("rename file: %w", err)
+	}
+
+	return nil
+}
+
+// Read opens the file for key.
+func (s *FileStorage) Read(key string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+
+	return file, nil
+}
+
+// Exists reports whether the file for key exists.
+func (s *FileStorage) Exists(key string) (bool, error) {
+	_, err := os.Stat(s.path(key))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	}
+
+	return false, fmt.Errorf("stat file: %w", err)
+}
+
+// Delete removes the file for key. Deleting a missing key is not an error.
+func (s *FileStorage) Delete(key string) error {
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove file: %w", err)
+	}
+
+	return nil
+}
+
+// Clear removes every file recorded in the index, along with the directories that are
+// left empty, and then the index itself. Files in the storage directory that were not
+// written by a FileStorage are kept.
+func (s *FileStorage) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.keys {
+		if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("remove file: %w", err)
+		}
+
+		s.removeEmptyDirs(filepath.Dir(s.path(key)))
+		delete(s.keys, key)
+	}
+
+	if err := os.Remove(s.path(storageIndexFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove index: %w", err)
+	}
+
+	return nil
+}
+
+// removeEmptyDirs removes dir and its parents up to the storage directory for as long as
+// they are empty.
+func (s *FileStorage) removeEmptyDirs(dir string) {
+	root := filepath.Clean(s.dir)
+
+	for dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+
+		dir = filepath.Dir(dir)
+	}
+}
+
+// NewFileStorage creates a FileStorage that saves files in dir, creating it if needed.
+func NewFileStorage(dir string) (*FileStorage, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("mkdir: %w", err)
+	}
+
+	storage := &FileStorage{
+		dir:  dir,
+		keys: make(map[string]struct{}),
+	}
+
+	if err := storage.loadIndex(); err != nil {
+		return nil, err
+	}
+
+	return storage, nil
+}
+
+// MemoryStorage is a Storage that keeps every key in memory. It is safe for concurrent use.
+type MemoryStorage struct {
+	pages sync.Map
+}
+
+// Write saves the contents of r for key, replacing any existing value.
+func (s *MemoryStorage) Write(key string, r io.Reader) error {
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read contents: %w", err)
+	}
+
+	s.pages.Store(key, contents)
+	return nil
+}
+
+// Read returns a reader over the contents saved for key.
+func (s *MemoryStorage) Read(key string) (io.ReadCloser, error) {
+	contents, ok := s.pages.Load(key)
+	if !ok {
+		return nil, fmt.Errorf("read %s: %w", key, fs.ErrNotExist)
+	}
+
+	return io.NopCloser(bytes.NewReader(contents.([]byte))), nil
+}
+
+// Exists reports whether key has been saved.
+func (s *MemoryStorage) Exists(key string) (bool, error) {
+	_, ok := s.pages.Load(key)
+	return ok, nil
+}
+
+// Delete removes key. Deleting a missing key is not an error.
+func (s *MemoryStorage) Delete(key string) error {
+	s.pages.Delete(key)
+	return nil
+}
+
+// Clear removes every key.
+func (s *MemoryStorage) Clear() error {
+	s.pages.Clear()
+	return nil
+}
+
+// NewMemoryStorage creates an empty MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{}
+}
@@ -0,0 +1,264 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	fileStorage, err := NewFileStorage(t.TempDir())
+	assert.Nil(t, err)
+
+	storages := map[string]Storage{
+		"file":   fileStorage,
+		"memory": NewMemoryStorage(),
+	}
+
+	for name, storage := range storages {
+		t.Run(name, func(t *testing.T) {
+			exists, err := storage.Exists("page")
+			assert.Nil(t, err)
+			assert.False(t, exists)
+
+			_, err = storage.Read("page")
+			assert.ErrorIs(t, err, fs.ErrNotExist)
+
+			assert.Nil(t, storage.Write("page", strings.NewReader("<p>hello</p>")))
+
+			exists, err = storage.Exists("page")
+			assert.Nil(t, err)
+			assert.True(t, exists)
+
+			reader, err := storage.Read("page")
+			assert.Nil(t, err)
+
+			contents, err := io.ReadAll(reader)
+			assert.Nil(t, err)
+			assert.Nil(t, reader.Close())
+			assert.Equal(t, string(contents), "<p>hello</p>")
+
+			assert.Nil(t, storage.Delete("page"))
+			assert.Nil(t, storage.Delete("page"))
+
+			exists, err = storage.Exists("page")
+			assert.Nil(t, err)
+			assert.False(t, exists)
+
+			assert.Nil(t, storage.Write("first", strings.NewReader("first")))
+			assert.Nil(t, storage.Write("second", strings.NewReader("second")))
+			assert.Nil(t, storage.(clearer).Clear())
+
+			for _, key := range []string{"first", "second"} {
+				exists, err = storage.Exists(key)
+				assert.Nil(t, err)
+				assert.False(t, exists)
+			}
+		})
+	}
+}
+
+func TestWithStorage(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		storage    = NewMemoryStorage()
+		dir        = t.TempDir() + "/unused"
+		requests   int
+	)
+
+	httpClient.Request(link, func() (code int, body string) {
+		requests++
+		return http.StatusOK, `<a href="/about">About</a>`
+	})
+
+	crawler, err := NewCrawler(httpClient, dir, WithStorage(storage))
+	assert.Nil(t, err)
+
+	links, err := crawler.Fetch(ctx, link)
+	assert.Nil(t, err)
+	assert.Equal(t, links, []string{link + "/about"})
+
+	reader, err := storage.Read("http_localhost_com")
+	assert.Nil(t, err)
+
+	contents, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, string(contents), `<a href="/about">About</a>`)
+
+	// The stored page is reused instead of downloading it again
+	_, err = crawler.Fetch(ctx, link)
+	assert.Nil(t, err)
+	assert.Equal(t, requests, 1)
+
+	_, err = os.Stat(dir)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+// failingReader returns its data and then fails, like a download that is interrupted.
+type failingReader struct {
+	data string
+	read bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.read {
+		return 0, errors.New("connection reset")
+	}
+
+	r.read = true
+	return copy(p, r.data), nil
+}
+
+func TestFileStorage_Clear(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "checkpoint.json"), []byte("{}"), 0o644))
+
+	storage, err := NewFileStorage(dir)
+	assert.Nil(t, err)
+
+	assert.Nil(t, storage.Write("page", strings.NewReader("page")))
+	assert.Nil(t, storage.Write("blog/post/index.html", strings.NewReader("post")))
+	assert.NotNil(t, storage.Write(storageIndexFile, strings.NewReader("index")))
+
+	// A new storage picks up the keys written by the previous one
+	storage, err = NewFileStorage(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, storage.Write("other", strings.NewReader("other")))
+	assert.Nil(t, storage.Clear())
+
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, len(entries), 1)
+	assert.Equal(t, entries[0].Name(), "checkpoint.json")
+
+	assert.Nil(t, storage.Write("page", strings.NewReader("page")))
+	assert.Nil(t, storage.Clear())
+
+	exists, err := storage.Exists("page")
+	assert.Nil(t, err)
+	assert.False(t, exists)
+}
+
+func TestFileStorage_AtomicWrite(t *testing.T) {
+	t.Run("keeps no partial file when writing fails", func(t *testing.T) {
+		dir := t.TempDir()
+
+		storage, err := NewFileStorage(dir)
+		assert.Nil(t, err)
+
+		assert.Nil(t, storage.Write("page", strings.NewReader("<p>complete</p>")))
+		assert.NotNil(t, storage.Write("page", &failingReader{data: "<p>part"}))
+		assert.NotNil(t, storage.Write("other", &failingReader{data: "<p>part"}))
+
+		contents, err := os.ReadFile(filepath.Join(dir, "page"))
+		assert.Nil(t, err)
+		assert.Equal(t, string(contents), "<p>complete</p>")
+
+		exists, err := storage.Exists("other")
+		assert.Nil(t, err)
+		assert.False(t, exists)
+
+		// The page and the storage index
+		entries, err := os.ReadDir(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, len(entries), 2)
+	})
+
+	t.Run("keeps files readable by everyone", func(t *testing.T) {
+		dir := t.TempDir()
+
+		storage, err := NewFileStorage(dir)
+		assert.Nil(t, err)
+		assert.Nil(t, storage.Write("page", strings.NewReader("<p>page</p>")))
+
+		info, err := os.Stat(filepath.Join(dir, "page"))
+		assert.Nil(t, err)
+		assert.Equal(t, info.Mode().Perm(), os.FileMode(0o644))
+	})
+
+	t.Run("keeps no partial file when a download is cancelled", func(t *testing.T) {
+		var (
+			dir         = t.TempDir()
+			ctx, cancel = context.WithCancel(context.Background())
+			started     = make(chan struct{})
+		)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "1000")
+			_, _ = w.Write([]byte("<p>partial"))
+			w.(http.Flusher).Flush()
+
+			close(started)
+			<-r.Context().Done()
+		}))
+		t.Cleanup(server.Close)
+
+		crawler, err := New(WithDestinationDir(dir))
+		assert.Nil(t, err)
+
+		go func() {
+			<-started
+			cancel()
+		}()
+
+		_, err = crawler.DownloadAndSave(ctx, server.URL, "page")
+		assert.NotNil(t, err)
+
+		_, err = os.Stat(filepath.Join(dir, "page"))
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+
+		entries, err := os.ReadDir(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, len(entries), 0)
+	})
+}
+
+func TestWithMemoryOnly(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		dir        = filepath.Join(t.TempDir(), "pages")
+		requests   atomic.Int32
+	)
+
+	httpClient.Request(link, func() (int, string) {
+		requests.Add(1)
+		return http.StatusOK, `<a href="/about">About</a>`
+	})
+
+	httpClient.Request(link+"/about", func() (int, string) {
+		requests.Add(1)
+		return http.StatusOK, `<p>About</p>`
+	})
+
+	crawler, err := NewCrawler(httpClient, dir, WithMemoryOnly(true))
+	assert.Nil(t, err)
+
+	report, err := crawler.Start(ctx, link, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, len(report.VisitedURLs), 2)
+
+	_, ok := crawler.storage.(*MemoryStorage)
+	assert.True(t, ok)
+
+	// Pages are served from memory once fetched
+	_, err = crawler.Fetch(ctx, link)
+	assert.Nil(t, err)
+	assert.Equal(t, requests.Load(), int32(2))
+
+	_, err = os.Stat(dir)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// inlineElements are the elements whose tags do not separate the words around them.
+var inlineElements = map[atom.Atom]struct{}{
+	atom.A:      {},
+	atom.Abbr:   {},
+	atom.B:      {},
+	atom.Cite:   {},
+	atom.Code:   {},
+	atom.Em:     {},
+	atom.I:      {},
+	atom.Label:  {},
+	atom.Mark:   {},
+	atom.Q:      {},
+	atom.S:      {},
+	atom.Small:  {},
+	atom.Span:   {},
+	atom.Strong: {},
+	atom.Sub:    {},
+	atom.Sup:    {},
+	atom.U:      {},
+}
+
+// ExtractText reads an HTML document and returns its visible text. The contents of
+// <script> and <style> tags are skipped, and runs of whitespace are collapsed into a
+// single space.
+func ExtractText(r io.Reader) (string, error) {
+	var (
+		tokenizer = html.NewTokenizer(r)
+		text      strings.Builder
+		skipping  atom.Atom
+	)
+
+	for {
+		tt := tokenizer.Next()
+
+		switch tt {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); !errors.Is(err, io.EOF) {
+				return "", fmt.Errorf("parse html: %w", err)
+			}
+
+			return strings.Join(strings.Fields(text.String()), " "), nil
+
+		case html.TextToken:
+			if skipping == 0 {
+				text.Write(tokenizer.Text())
+			}
+
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+
+			switch {
+			case tt == html.StartTagToken && skipping == 0 && (token.DataAtom == atom.Script || token.DataAtom == atom.Style):
+				skipping = token.DataAtom
+			case tt == html.EndTagToken && token.DataAtom == skipping:
+				skipping = 0
+			}
+
+			// Block elements such as paragraphs separate the words around them
+			if _, inline := inlineElements[token.DataAtom]; !inline {
+				text.WriteByte(' ')
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package crawler
+
+import (
+	"context"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestExtractText(t *testing.T) {
+	t.Run("extracts visible text", func(t *testing.T) {
+		page := `
+			<html>
+			<head>
+				<title>Recipes</title>
+				<style>p { color: red; }</style>
+				<script>var recipes = "<p>hidden</p>";</script>
+			</head>
+			<body>
+				<h1>Pancakes</h1>
+				<p>Mix the   flour
+					and the <strong>milk</strong>.</p>
+				<p>Cook &amp; serve.</p>
+			</body>
+			</html>`
+
+		text, err := ExtractText(strings.NewReader(page))
+		assert.Nil(t, err)
+		assert.Equal(t, text, "Recipes Pancakes Mix the flour and the milk. Cook & serve.")
+	})
+
+	t.Run("attaches text to fetched pages when enabled", func(t *testing.T) {
+		var (
+			link       = "http://localhost.com"
+			httpClient = testutil.NewTestHttpClient()
+			ctx        = context.Background()
+		)
+
+		httpClient.Request(link, func() (int, string) {
+			return http.StatusOK, `<p>Hello</p><p>world</p>`
+		})
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(), WithExtractText(true))
+		assert.Nil(t, err)
+
+		report, err := crawler.Start(ctx, link, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, report.VisitedURLs[0].TextContent, "Hello world")
+
+		crawler, err = NewCrawler(httpClient, t.TempDir())
+		assert.Nil(t, err)
+
+		report, err = crawler.Start(ctx, link, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, report.VisitedURLs[0].TextContent, "")
+	})
+}
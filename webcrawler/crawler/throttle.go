@@ -0,0 +1,46 @@
+package crawler
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// ThrottledReader is an io.Reader that limits the rate at which bytes are read from the
+// underlying reader. Readers sharing a rate.Limiter share its bandwidth.
+type ThrottledReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *rate.Limiter
+}
+
+// NewThrottledReader returns a ThrottledReader that reads from r no faster than limiter
+// allows, with one token per byte. Reads fail with the context error once ctx is done.
+func NewThrottledReader(ctx context.Context, r io.Reader, limiter *rate.Limiter) *ThrottledReader {
+	return &ThrottledReader{ctx: ctx, reader: r, limiter: limiter}
+}
+
+// Read reads up to the limiter's burst size and waits until the bytes read are allowed.
+func (r *ThrottledReader) Read(p []byte) (int, error) {
+	if burst := r.limiter.Burst(); burst > 0 && len(p) > burst {
+		p = p[:burst]
+	}
+
+	n, err := r.reader.Read(p)
+	if n <= 0 {
+		return n, err
+	}
+
+	if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+		return n, waitErr
+	}
+
+	return n, err
+}
+
+// newBandwidthLimiter returns a limiter for bytesPerSecond with a burst of a tenth of a
+// second's worth of bytes.
+func newBandwidthLimiter(bytesPerSecond int64) *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), int(max(bytesPerSecond/10, 1)))
+}
@@ -0,0 +1,86 @@
+package crawler
+
+import (
+	"context"
+	"kitchen/pkg/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithBandwidthLimit(t *testing.T) {
+	const (
+		size           = 4096
+		bytesPerSecond = 8192
+		burst          = bytesPerSecond / 10
+	)
+
+	body := strings.Repeat("a", size)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	// expected returns the time to read n bytes at the limit, once the initial burst is spent
+	expected := func(n int) time.Duration {
+		return time.Duration(float64(n-burst) / bytesPerSecond * float64(time.Second))
+	}
+
+	assertWithin := func(t *testing.T, elapsed, want time.Duration) {
+		t.Helper()
+
+		if elapsed < want*8/10 || elapsed > want*12/10 {
+			t.Errorf("elapsed %s, want %s within 20%%", elapsed, want)
+		}
+	}
+
+	t.Run("limits a download", func(t *testing.T) {
+		crawler, err := New(WithDestinationDir(t.TempDir()), WithBandwidthLimit(bytesPerSecond))
+		assert.Nil(t, err)
+
+		start := time.Now()
+
+		buffer, err := crawler.DownloadAndSave(ctx, server.URL, "page")
+		assert.Nil(t, err)
+		assert.Equal(t, buffer.Len(), size)
+
+		assertWithin(t, time.Since(start), expected(size))
+	})
+
+	t.Run("shares the limit between downloads", func(t *testing.T) {
+		crawler, err := New(WithDestinationDir(t.TempDir()), WithBandwidthLimit(bytesPerSecond))
+		assert.Nil(t, err)
+
+		var (
+			wg    sync.WaitGroup
+			start = time.Now()
+		)
+
+		for _, key := range []string{"first", "second"} {
+			wg.Go(func() {
+				_, err := crawler.DownloadAndSave(ctx, server.URL, key)
+				assert.Nil(t, err)
+			})
+		}
+
+		wg.Wait()
+		assertWithin(t, time.Since(start), expected(2*size))
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		crawler, err := New(WithDestinationDir(t.TempDir()), WithBandwidthLimit(1))
+		assert.Nil(t, err)
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		_, err = crawler.DownloadAndSave(ctx, server.URL, "page")
+		assert.NotNil(t, err)
+	})
+}
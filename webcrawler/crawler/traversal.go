@@ -0,0 +1,56 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+)
+
+// TraversalStrategy determines the order in which discovered links are crawled.
+type TraversalStrategy string
+
+const (
+	// TraversalDFS crawls the links of a page as soon as the page is fetched, going deep
+	// before wide. It is the default strategy.
+	TraversalDFS TraversalStrategy = "dfs"
+	// TraversalBFS crawls every page at one depth before any page at the next depth.
+	TraversalBFS TraversalStrategy = "bfs"
+)
+
+// crawlBFS crawls the pending URLs level by level. The pages of a level are fetched
+// concurrently, and the links they contain form the next level. Only the pending URLs
+// in seeds are starting URLs.
+func (c *Crawler) crawlBFS(ctx context.Context, pending map[string]int, seeds map[string]struct{}) {
+	queue := make([]frontierItem, 0, len(pending))
+	for link, depth := range pending {
+		queue = append(queue, frontierItem{URL: link, Depth: depth})
+	}
+
+	for len(queue) > 0 && ctx.Err() == nil {
+		var (
+			mu   sync.Mutex
+			wg   sync.WaitGroup
+			next []frontierItem
+		)
+
+		for _, item := range queue {
+			_, seed := seeds[item.URL]
+
+			wg.Go(func() {
+				links, ok := c.visit(ctx, item.URL, item.Depth, seed)
+				if !ok || item.Depth <= 1 {
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				for _, link := range links {
+					next = append(next, frontierItem{URL: link, Depth: item.Depth - 1})
+				}
+			})
+		}
+
+		wg.Wait()
+		queue = next
+	}
+}
@@ -0,0 +1,85 @@
+package crawler
+
+import (
+	"context"
+	"kitchen/pkg/assert"
+	"kitchen/pkg/testutil"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestCrawler_Traversal(t *testing.T) {
+	var (
+		link       = "http://localhost.com"
+		httpClient = testutil.NewTestHttpClient()
+		ctx        = context.Background()
+		pages      = map[string]string{
+			link:          `<a href="/b">B</a><a href="/c">C</a>`,
+			link + "/b":   `<a href="/b/d">D</a>`,
+			link + "/c":   `<a href="/b/d">D</a>`,
+			link + "/b/d": `<p>D</p>`,
+		}
+	)
+
+	for page, body := range pages {
+		httpClient.Request(page, func() (int, string) {
+			return http.StatusOK, body
+		})
+	}
+
+	tests := []struct {
+		name     string
+		strategy TraversalStrategy
+	}{
+		{name: "dfs", strategy: TraversalDFS},
+		{name: "bfs", strategy: TraversalBFS},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crawler, err := NewCrawler(httpClient, t.TempDir(),
+				WithTraversal(tt.strategy),
+				WithDomainWhitelist("localhost.com"),
+			)
+			assert.Nil(t, err)
+
+			report, err := crawler.Start(ctx, link, 3)
+			assert.Nil(t, err)
+
+			urls := report.URLs()
+			slices.Sort(urls)
+			assert.Equal(t, urls, []string{link, link + "/b", link + "/b/d", link + "/c"})
+
+			for _, page := range report.VisitedURLs {
+				if page.URL == link+"/b/d" {
+					assert.Equal(t, page.Depth, 2)
+				}
+			}
+		})
+	}
+
+	t.Run("bfs visits pages in depth order", func(t *testing.T) {
+		var (
+			mu     sync.Mutex
+			depths []int
+		)
+
+		crawler, err := NewCrawler(httpClient, t.TempDir(),
+			WithTraversal(TraversalBFS),
+			WithDomainWhitelist("localhost.com"),
+			WithOnPageFetched(func(page PageResult) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				depths = append(depths, page.Depth)
+			}),
+		)
+		assert.Nil(t, err)
+
+		_, err = crawler.Start(ctx, link, 3)
+		assert.Nil(t, err)
+		assert.True(t, slices.IsSorted(depths))
+	})
+}